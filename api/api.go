@@ -2,12 +2,19 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/ONSdigital/dis-redirect-api/config"
+	"github.com/ONSdigital/dis-redirect-api/models"
 	"github.com/ONSdigital/dis-redirect-api/store"
 	"github.com/ONSdigital/dp-authorisation/v2/authorisation"
+	"github.com/ONSdigital/dp-authorisation/v2/zebedeeclient"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 )
@@ -17,7 +24,25 @@ type RedirectAPI struct {
 	Router         *mux.Router
 	RedirectStore  *store.Datastore
 	authMiddleware authorisation.Middleware
+	zebedeeClient  authorisation.ZebedeeClient
 	apiURL         *url.URL
+
+	serviceIdentities serviceIdentities
+
+	// externalHosts and externalSchemes allow-list the absolute URLs that redirects can target
+	externalHosts   []string
+	externalSchemes []string
+
+	normalisePaths    bool
+	normalisePathCase bool
+
+	chainMaxDepth    int
+	rejectLongChains bool
+
+	resolveMaxHops int
+
+	bulkMaxBodySize int64
+	bulkMaxItems    int
 }
 
 // Setup function sets up the api and returns an api
@@ -32,16 +57,61 @@ func Setup(ctx context.Context, r *mux.Router, dataStore *store.Datastore, auth
 		RedirectStore:  dataStore,
 		authMiddleware: auth,
 		apiURL:         apiURL,
+
+		normalisePaths:    cfg.NormalisePaths,
+		normalisePathCase: cfg.NormalisePathCase,
+
+		chainMaxDepth:    cfg.RedirectChainMaxDepth,
+		rejectLongChains: cfg.RedirectChainPolicy == config.RedirectChainPolicyReject,
+
+		resolveMaxHops: cfg.ResolveMaxHops,
+
+		bulkMaxBodySize: cfg.BulkMaxBodySize,
+		bulkMaxItems:    cfg.BulkMaxItems,
+	}
+
+	for _, host := range cfg.ExternalRedirectHosts {
+		api.externalHosts = append(api.externalHosts, strings.ToLower(strings.TrimSpace(host)))
+	}
+	for _, scheme := range cfg.ExternalRedirectSchemes {
+		api.externalSchemes = append(api.externalSchemes, strings.ToLower(strings.TrimSpace(scheme)))
+	}
+
+	// the zebedee client is only needed to identify services when authorisation is enabled
+	if cfg.AuthorisationConfig != nil && cfg.AuthorisationConfig.Enabled {
+		api.zebedeeClient = zebedeeclient.NewZebedeeClient(cfg.AuthorisationConfig.ZebedeeURL)
 	}
 
 	api.get("/v1/redirects/{id}", auth.Require("redirects:read", api.getRedirect))
 
+	api.getByPath("/v1/redirects", auth.Require("redirects:read", api.getRedirect))
+
 	api.get("/v1/redirects", auth.Require("redirects:read", api.getRedirects))
 
+	api.post("/v1/redirects", auth.Require("redirects:edit", api.createRedirect))
+
+	api.get("/v1/resolve", auth.Require("redirects:read", api.resolve))
+
+	api.get("/v1/maintenance/collisions", auth.Require("redirects:read", api.getCollisions))
+
+	api.post("/v1/maintenance/collapse-chains", auth.Require("redirects:edit", api.collapseChains))
+
+	api.post("/v1/maintenance/rebuild-indexes", auth.Require("redirects:edit", api.rebuildIndexes))
+
+	api.get("/v1/bulk/export", auth.Require("redirects:read", api.exportRedirects))
+
+	api.post("/v1/bulk/import", auth.Require("redirects:edit", api.importRedirects))
+
+	api.post("/v1/bulk/delete", auth.Require("redirects:delete", api.deleteRedirects))
+
 	api.put("/v1/redirects/{id}", auth.Require("redirects:edit", api.UpsertRedirect))
 
+	api.patch("/v1/redirects/{id}", auth.Require("redirects:edit", api.patchRedirect))
+
 	api.delete("/v1/redirects/{id}", auth.Require("redirects:delete", api.DeleteRedirect))
 
+	api.deleteByPath("/v1/redirects", auth.Require("redirects:delete", api.DeleteRedirect))
+
 	return api
 }
 
@@ -50,16 +120,87 @@ func (api *RedirectAPI) get(path string, handler http.HandlerFunc) {
 	api.Router.HandleFunc(path, handler).Methods(http.MethodGet)
 }
 
+// getByPath registers a GET http.HandlerFunc that is only used when the 'path' query parameter is given, so it
+// must be registered before any handler for the same path without it
+func (api *RedirectAPI) getByPath(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, handler).Methods(http.MethodGet).Queries(QueryParameterPath, "{path}")
+}
+
+func (api *RedirectAPI) post(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, handler).Methods(http.MethodPost)
+}
+
 func (api *RedirectAPI) put(path string, handler http.HandlerFunc) {
 	api.Router.HandleFunc(path, handler).Methods(http.MethodPut)
 }
 
+func (api *RedirectAPI) patch(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, handler).Methods(http.MethodPatch)
+}
+
 func (api *RedirectAPI) delete(path string, handler http.HandlerFunc) {
 	api.Router.HandleFunc(path, handler).Methods(http.MethodDelete)
 }
 
-// handleError returns the specified error message and HTTP code
+// deleteByPath registers a DELETE http.HandlerFunc that is only used when the 'path' query parameter is given
+func (api *RedirectAPI) deleteByPath(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, handler).Methods(http.MethodDelete).Queries(QueryParameterPath, "{path}")
+}
+
+// longRequestTimeout is how long a request that reads or writes many redirects is given each time it reads or
+// writes more of them, as the timeouts of the server only allow long enough for a request about a single redirect
+const longRequestTimeout = 30 * time.Second
+
+// connContextKey is the context key for the connection a request was received on
+type connContextKey struct{}
+
+// ConnContext adds the connection a request is received on to the context of the request, so that the deadlines
+// of the connection can be extended by the requests that read or write many redirects. It is set as the
+// ConnContext of the HTTP server, as the response writers wrapped by the middleware cannot have their deadlines
+// changed.
+func ConnContext(ctx context.Context, conn net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, conn)
+}
+
+// extendDeadlines gives a request that reads or writes many redirects until longRequestTimeout from now to read
+// more of its body or write more of its response. It is called each time more is read or written, so that a
+// client that stops reading or writing is still timed out. The deadlines of the connection added by ConnContext
+// are used if there is one, and otherwise those of the response writer, which are left as they are if they
+// cannot be changed.
+func extendDeadlines(ctx context.Context, w http.ResponseWriter) {
+	deadline := time.Now().Add(longRequestTimeout)
+
+	// the errors only say that the deadlines cannot be changed, so there is nothing more that can be done
+	if conn, ok := ctx.Value(connContextKey{}).(net.Conn); ok {
+		_ = conn.SetReadDeadline(deadline)
+		_ = conn.SetWriteDeadline(deadline)
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline)
+}
+
+// handleError returns the specified error and HTTP code, with a JSON body giving the code that identifies the
+// error, its message and the ID of the request
 func (api *RedirectAPI) handleError(ctx context.Context, w http.ResponseWriter, err error, status int) {
 	log.Error(ctx, "request failed", err)
-	http.Error(w, err.Error(), status)
+
+	errorResponse := models.ErrorResponse{
+		Code:      errorCode(err, status),
+		Message:   err.Error(),
+		RequestID: dprequest.GetRequestId(ctx),
+	}
+	writeErrorResponse(ctx, w, errorResponse, status)
+}
+
+// writeErrorResponse writes the given error response as the body with the given status code
+func writeErrorResponse(ctx context.Context, w http.ResponseWriter, errorResponse models.ErrorResponse, status int) {
+	w.Header().Set("Content-Type", mediaTypeJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(errorResponse); err != nil {
+		log.Error(ctx, "failed to write error response", err)
+	}
 }
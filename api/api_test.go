@@ -7,6 +7,7 @@ import (
 
 	"github.com/ONSdigital/dis-redirect-api/store"
 	authorisation "github.com/ONSdigital/dp-authorisation/v2/authorisation/mock"
+	permsdk "github.com/ONSdigital/dp-permissions-api/sdk"
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -18,8 +19,18 @@ func TestSetup(t *testing.T) {
 
 		Convey("When created the following routes should have been added", func() {
 			So(hasRoute(redirectAPI.Router, "/v1/redirects/{id}", "PUT"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/redirects/{id}", "PATCH"), ShouldBeTrue)
 			So(hasRoute(redirectAPI.Router, "/v1/redirects/{id}", "GET"), ShouldBeTrue)
 			So(hasRoute(redirectAPI.Router, "/v1/redirects/{id}", "DELETE"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/redirects", "POST"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/redirects?path=/economy", "GET"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/redirects?path=/economy", "DELETE"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/resolve", "GET"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/maintenance/collisions", "GET"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/bulk/export", "GET"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/bulk/import", "POST"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/bulk/delete", "POST"), ShouldBeTrue)
+			So(hasRoute(redirectAPI.Router, "/v1/maintenance/collapse-chains", "POST"), ShouldBeTrue)
 		})
 	})
 }
@@ -30,10 +41,18 @@ func hasRoute(r *mux.Router, path, method string) bool {
 	return r.Match(req, match)
 }
 
+const (
+	testUserToken = "header.payload.signature"
+	testUserID    = "janedoe@ons.gov.uk"
+)
+
 func newAuthMiddlwareMock() *authorisation.MiddlewareMock {
 	return &authorisation.MiddlewareMock{
 		RequireFunc: func(_ string, handlerFunc http.HandlerFunc) http.HandlerFunc {
 			return handlerFunc
 		},
+		ParseFunc: func(_ string) (*permsdk.EntityData, error) {
+			return &permsdk.EntityData{UserID: testUserID}, nil
+		},
 	}
 }
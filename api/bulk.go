@@ -0,0 +1,505 @@
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// The media types that redirects can be imported from
+const (
+	mediaTypeJSON = "application/json"
+	mediaTypeCSV  = "text/csv"
+)
+
+// The columns of a CSV import or export, named after the fields of a redirect
+const (
+	columnID          = "id"
+	columnHost        = "host"
+	columnFrom        = "from"
+	columnTo          = "to"
+	columnType        = "type"
+	columnStatusCode  = "status_code"
+	columnQueryPolicy = "query_policy"
+	columnTTL         = "ttl"
+	columnExpiresAt   = "expires_at"
+	columnValidFrom   = "valid_from"
+	columnValidUntil  = "valid_until"
+	columnStatus      = "status"
+	columnCreatedAt   = "created_at"
+	columnCreatedBy   = "created_by"
+	columnUpdatedAt   = "updated_at"
+	columnUpdatedBy   = "updated_by"
+)
+
+// importColumns are the columns a CSV import can have. The columns that are set for false are written by an
+// export but are not fields that can be set, so they are ignored when the export is imported again.
+var importColumns = map[string]bool{
+	columnID:          false,
+	columnHost:        true,
+	columnFrom:        true,
+	columnTo:          true,
+	columnType:        true,
+	columnStatusCode:  true,
+	columnQueryPolicy: true,
+	columnTTL:         true,
+	columnExpiresAt:   true,
+	columnValidFrom:   true,
+	columnValidUntil:  true,
+	columnStatus:      false,
+	columnCreatedAt:   false,
+	columnCreatedBy:   false,
+	columnUpdatedAt:   false,
+	columnUpdatedBy:   false,
+}
+
+// importRow is a single redirect read from an import, or the reason it could not be read
+type importRow struct {
+	redirect models.Redirect
+	err      error
+}
+
+// importRedirects handles importing redirects in bulk from a JSON array or a CSV file. Each redirect is
+// validated in the same way as when it is created or updated on its own, and the outcome for each is reported.
+// In atomic mode, the redirects are only imported if every one of them is valid.
+func (api *RedirectAPI) importRedirects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	atomic := false
+	if strAtomic := r.URL.Query().Get(QueryParameterAtomic); strAtomic != "" {
+		var err error
+		atomic, err = strconv.ParseBool(strAtomic)
+		if err != nil {
+			log.Info(ctx, "invalid query parameter - atomic should be a boolean", log.Data{QueryParameterAtomic: strAtomic})
+			api.handleError(ctx, w, ErrInvalidAtomic, http.StatusBadRequest)
+			return
+		}
+	}
+	logData := log.Data{QueryParameterAtomic: atomic}
+
+	extendDeadlines(ctx, w)
+	r.Body = http.MaxBytesReader(w, r.Body, api.bulkMaxBodySize)
+	rows, status, err := api.readImportRows(r)
+	if err != nil {
+		logData["reason"] = err.Error()
+		log.Info(ctx, "invalid redirect import", logData)
+		api.handleError(ctx, w, err, status)
+		return
+	}
+	logData["num_rows"] = len(rows)
+
+	identity, err := api.getCallerIdentity(r)
+	if err != nil {
+		log.Error(ctx, "failed to get the identity of the caller", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	results, importErr := api.importRows(ctx, rows, identity, atomic, time.Now().UTC())
+	extendDeadlines(ctx, w)
+	if importErr != nil {
+		log.Error(ctx, "redis failed on importing redirects", importErr, logData)
+		if results == nil {
+			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	report := models.ImportReport{Items: []models.ImportResult{}}
+	for _, result := range results {
+		report.Add(result)
+	}
+	log.Info(ctx, "redirects imported", log.Data{"created": report.Created, "updated": report.Updated, "rejected": report.Rejected})
+
+	status = http.StatusOK
+	if atomic && report.Rejected > 0 {
+		status = http.StatusBadRequest
+	}
+	if importErr != nil {
+		status = http.StatusInternalServerError
+	}
+
+	reportResponse, err := json.Marshal(report)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaTypeJSON)
+	w.WriteHeader(status)
+	if _, err = w.Write(reportResponse); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+	}
+}
+
+// importRows validates and saves each of the given rows in turn, returning the outcome for each. Rows are saved
+// in order, so a row can lead to a redirect saved by an earlier row. In atomic mode, no rows are saved unless
+// every row is valid, and the rows already saved are put back as they were if a row turns out to create a loop
+// or a chain that is too long, or keeps being changed by other requests. A saved row is left as it is, with a
+// warning, if another request has changed its redirect since. If the store fails while a row is being saved, no
+// more rows are saved, and the error is returned along with the outcome of each row so far, after the rows
+// already saved are put back in atomic mode. Only the error is returned if they cannot be put back.
+func (api *RedirectAPI) importRows(ctx context.Context, rows []importRow, identity string, atomic bool, now time.Time) ([]models.ImportResult, error) {
+	results := make([]models.ImportResult, len(rows))
+	rejected := false
+	rowByKey := map[string]int{}
+
+	for i := range rows {
+		row := &rows[i]
+		err := row.err
+		if err == nil {
+			err = api.validateRedirect(&row.redirect)
+		}
+		if err == nil {
+			_, err = getExpiration(&row.redirect, now)
+		}
+		if err == nil {
+			if first, ok := rowByKey[row.redirect.Key()]; ok {
+				err = fmt.Errorf("%w: row %d", ErrDuplicateImportRow, first+1)
+			}
+			rowByKey[row.redirect.Key()] = i
+		}
+
+		results[i] = models.ImportResult{Row: i + 1, Host: row.redirect.Host, From: row.redirect.From}
+		if err != nil {
+			results[i].Result = models.ImportResultRejected
+			results[i].Error = err.Error()
+			rejected = true
+		}
+	}
+
+	if atomic && rejected {
+		return skipValidRows(results), nil
+	}
+
+	// the rows saved so far and the redirects they replaced, so they can be put back in atomic mode
+	var imported []int
+	var replaced []*models.Redirect
+	for i := range rows {
+		if results[i].Result == models.ImportResultRejected {
+			continue
+		}
+
+		redirect := &rows[i].redirect
+		outcome, status, err := api.saveRedirect(ctx, redirect, preconditions{}, identity, now)
+		if err != nil {
+			results[i].Result = models.ImportResultRejected
+			results[i].Error = err.Error()
+
+			// the store failing stops the import, as the rows after this one would most likely fail too
+			var storeErr error
+			if status != http.StatusBadRequest && status != http.StatusConflict {
+				storeErr = err
+			}
+
+			if atomic {
+				results, err := api.rollBackImport(ctx, rows, results, imported, replaced, now)
+				if err != nil {
+					return nil, err
+				}
+				return results, storeErr
+			}
+			if storeErr != nil {
+				skipValidRows(results[i+1:])
+				return results, storeErr
+			}
+			continue
+		}
+		imported = append(imported, i)
+		replaced = append(replaced, outcome.previous)
+
+		results[i].ID = models.RedirectID(redirect.Key())
+		results[i].Result = models.ImportResultUpdated
+		if outcome.previous == nil {
+			results[i].Result = models.ImportResultCreated
+		}
+		if outcome.longChain {
+			results[i].Warning = ErrRedirectChainTooLong.Error()
+		}
+	}
+
+	return results, nil
+}
+
+// rollBackImport puts back the redirects replaced by the imported rows, and marks every row that was not rejected
+// as skipped, apart from those whose redirects could not be put back
+func (api *RedirectAPI) rollBackImport(ctx context.Context, rows []importRow, results []models.ImportResult, imported []int, replaced []*models.Redirect, now time.Time) ([]models.ImportResult, error) {
+	kept, err := api.restoreRedirects(ctx, rows, imported, replaced, now)
+	if err != nil {
+		return nil, err
+	}
+
+	keptResults := make([]models.ImportResult, len(kept))
+	for i, row := range kept {
+		keptResults[i] = results[row]
+		keptResults[i].Warning = ErrImportNotRolledBack.Error()
+	}
+
+	results = skipValidRows(results)
+	for _, result := range keptResults {
+		results[result.Row-1] = result
+	}
+
+	return results, nil
+}
+
+// restoreRedirects puts back the redirects replaced by the imported rows, deleting the imported redirects that did
+// not replace one, in the reverse order to which they were imported. A redirect is only put back while it is still
+// as it was imported, so that changes made by other requests since are not lost, and the rows whose redirects had
+// been changed, so were left as they are, are returned.
+func (api *RedirectAPI) restoreRedirects(ctx context.Context, rows []importRow, imported []int, replaced []*models.Redirect, now time.Time) ([]int, error) {
+	var kept []int
+	for i := len(imported) - 1; i >= 0; i-- {
+		redirect := &rows[imported[i]].redirect
+
+		err := api.restoreRedirect(ctx, redirect, replaced[i], now)
+		if errors.Is(err, store.ErrValueChanged) {
+			logData := log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From}
+			log.Warn(ctx, "imported redirect was changed by another request so is not being put back", logData)
+			kept = append(kept, imported[i])
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return kept, nil
+}
+
+// restoreRedirect puts back the redirect replaced by an imported redirect, or deletes the imported redirect if it
+// did not replace one. store.ErrValueChanged is returned if the imported redirect has been changed or deleted by
+// another request since it was saved.
+func (api *RedirectAPI) restoreRedirect(ctx context.Context, redirect, previous *models.Redirect, now time.Time) error {
+	current, version, err := api.RedirectStore.GetRedirectWithVersion(ctx, redirect.Key())
+	if errors.Is(err, disRedis.ErrKeyNotFound) {
+		return store.ErrValueChanged
+	}
+	if err != nil {
+		return err
+	}
+	if current.ETag() != redirect.ETag() {
+		return store.ErrValueChanged
+	}
+
+	var expiration time.Duration
+	if previous != nil && previous.ExpiresAt != nil {
+		expiration = previous.ExpiresAt.Sub(now)
+	}
+
+	if previous == nil || previous.ExpiresAt != nil && expiration <= 0 {
+		return api.RedirectStore.DeleteRedirectIfUnchanged(ctx, redirect.Key(), version)
+	}
+
+	return api.RedirectStore.UpdateRedirect(ctx, previous, version, expiration)
+}
+
+// skipValidRows marks every row that was not rejected as skipped
+func skipValidRows(results []models.ImportResult) []models.ImportResult {
+	for i := range results {
+		if results[i].Result != models.ImportResultRejected {
+			results[i] = models.ImportResult{
+				Row:    results[i].Row,
+				Host:   results[i].Host,
+				From:   results[i].From,
+				Result: models.ImportResultSkipped,
+			}
+		}
+	}
+
+	return results
+}
+
+// readImportRows reads the redirects to import from the body of the request, as a JSON array or a CSV file
+// depending on its content type. Rows that cannot be read are returned with the reason. An error is returned,
+// along with the status code to report it with, if the body as a whole cannot be read, or as soon as it is found
+// to hold more than the maximum number of redirects.
+func (api *RedirectAPI) readImportRows(r *http.Request) ([]importRow, int, error) {
+	mediaType := mediaTypeJSON
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType
+		}
+	}
+
+	var rows []importRow
+	var err error
+	switch mediaType {
+	case mediaTypeJSON:
+		rows, err = readJSONImportRows(r.Body, api.bulkMaxItems)
+	case mediaTypeCSV:
+		rows, err = readCSVImportRows(r.Body, api.bulkMaxItems)
+	default:
+		return nil, http.StatusUnsupportedMediaType, ErrUnsupportedMediaType
+	}
+	if errors.Is(err, ErrRequestBodyTooLarge) {
+		return nil, http.StatusRequestEntityTooLarge, err
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	return rows, 0, nil
+}
+
+// readJSONImportRows reads the redirects to import from a JSON array one at a time, so that it stops reading
+// once there are more than the maximum number of redirects
+func readJSONImportRows(body io.Reader, maxItems int) ([]importRow, error) {
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, bodyError(err, ErrInvalidRequestBody)
+	}
+
+	rows := []importRow{}
+	for decoder.More() {
+		if len(rows) == maxItems {
+			return nil, ErrTooManyItems
+		}
+
+		var item json.RawMessage
+		if err := decoder.Decode(&item); err != nil {
+			return nil, bodyError(err, ErrInvalidRequestBody)
+		}
+
+		var row importRow
+		if err := json.Unmarshal(item, &row.redirect); err != nil {
+			row.err = ErrInvalidRequestBody
+		}
+		rows = append(rows, row)
+	}
+
+	// read the end of the array
+	if _, err := decoder.Token(); err != nil {
+		return nil, bodyError(err, ErrInvalidRequestBody)
+	}
+
+	return rows, nil
+}
+
+// readCSVImportRows reads the redirects to import from a CSV file, whose header names the field of a redirect
+// held in each column, as a CSV export does. It stops reading once there are more than the maximum number of
+// redirects.
+func readCSVImportRows(body io.Reader, maxItems int) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, bodyError(err, ErrInvalidCSV)
+	}
+
+	seen := map[string]bool{}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := importColumns[column]; !ok || seen[column] {
+			return nil, ErrInvalidImportColumns
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	if !seen[columnFrom] || !seen[columnTo] {
+		return nil, ErrInvalidImportColumns
+	}
+
+	rows := []importRow{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, bodyError(err, ErrInvalidCSV)
+		}
+		if len(rows) == maxItems {
+			return nil, ErrTooManyItems
+		}
+
+		var row importRow
+		if len(record) != len(header) {
+			row.err = ErrInvalidImportRow
+		} else {
+			row.redirect, row.err = parseCSVImportRow(header, record)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// bodyError returns ErrRequestBodyTooLarge if the given error came from reading more of the request body than is
+// allowed, or otherwise the error to report the body as invalid with
+func bodyError(err, invalid error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return ErrRequestBodyTooLarge
+	}
+
+	return invalid
+}
+
+// parseCSVImportRow builds a redirect from a row of a CSV import. Empty values are left unset, as are the
+// values of columns that cannot be imported.
+func parseCSVImportRow(header, record []string) (models.Redirect, error) {
+	var redirect models.Redirect
+
+	for i, column := range header {
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+
+		var err error
+		switch column {
+		case columnHost:
+			redirect.Host = value
+		case columnFrom:
+			redirect.From = value
+		case columnTo:
+			redirect.To = value
+		case columnType:
+			redirect.Type = value
+		case columnQueryPolicy:
+			redirect.QueryPolicy = value
+		case columnStatusCode:
+			redirect.StatusCode, err = strconv.Atoi(value)
+		case columnTTL:
+			redirect.TTL, err = strconv.ParseInt(value, 10, 64)
+		case columnExpiresAt:
+			redirect.ExpiresAt, err = parseImportTime(value)
+		case columnValidFrom:
+			redirect.ValidFrom, err = parseImportTime(value)
+		case columnValidUntil:
+			redirect.ValidUntil, err = parseImportTime(value)
+		}
+		if err != nil {
+			return redirect, fmt.Errorf("%w: %s", ErrInvalidImportValue, column)
+		}
+	}
+
+	return redirect, nil
+}
+
+// parseImportTime parses a time given in RFC 3339 format, as it is in JSON
+func parseImportTime(value string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// deleteItem is a single redirect to delete, identified by its key, or the reason it could not be identified
+type deleteItem struct {
+	id  string
+	key string
+	err error
+}
+
+// deleteRedirects handles deleting redirects in bulk, either those listed by id or path or those whose 'from'
+// path or pattern starts with a prefix, and reports the outcome for each. In a dry run, the redirects that would
+// be deleted are reported but nothing is deleted.
+func (api *RedirectAPI) deleteRedirects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dryRun := false
+	if strDryRun := r.URL.Query().Get(QueryParameterDryRun); strDryRun != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(strDryRun)
+		if err != nil {
+			log.Info(ctx, "invalid query parameter - dry_run should be a boolean", log.Data{QueryParameterDryRun: strDryRun})
+			api.handleError(ctx, w, ErrInvalidDryRun, http.StatusBadRequest)
+			return
+		}
+	}
+
+	var request models.BulkDelete
+	r.Body = http.MaxBytesReader(w, r.Body, api.bulkMaxBodySize)
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		log.Info(ctx, "failed to decode request body", log.Data{"error": err.Error()})
+		if err := bodyError(err, ErrInvalidRequestBody); errors.Is(err, ErrRequestBodyTooLarge) {
+			api.handleError(ctx, w, err, http.StatusRequestEntityTooLarge)
+			return
+		}
+		api.handleError(ctx, w, ErrInvalidRequestBody, http.StatusBadRequest)
+		return
+	}
+
+	numListed := len(request.IDs) + len(request.Paths)
+	logData := log.Data{
+		QueryParameterDryRun: dryRun,
+		QueryParameterHost:   request.Host,
+		QueryParameterPrefix: request.Prefix,
+		"all_hosts":          request.AllHosts,
+		"num_listed":         numListed,
+	}
+
+	if (numListed > 0) == (request.Prefix != "") {
+		log.Info(ctx, "invalid bulk delete - either a list or a prefix should be provided", logData)
+		api.handleError(ctx, w, ErrInvalidBulkDelete, http.StatusBadRequest)
+		return
+	}
+
+	if request.Host != "" && !models.IsValidHost(request.Host) {
+		log.Info(ctx, "invalid bulk delete - host should be a lowercase hostname", logData)
+		api.handleError(ctx, w, ErrInvalidHost, http.StatusBadRequest)
+		return
+	}
+
+	if request.AllHosts && (request.Prefix == "" || request.Host != "") {
+		log.Info(ctx, "invalid bulk delete - all_hosts can only be set with a prefix and no host", logData)
+		api.handleError(ctx, w, ErrInvalidAllHosts, http.StatusBadRequest)
+		return
+	}
+
+	if !isValidPrefix(request.Prefix) {
+		log.Info(ctx, "invalid bulk delete - prefix should start with a '/' or the regex anchor", logData)
+		api.handleError(ctx, w, ErrInvalidPrefix, http.StatusBadRequest)
+		return
+	}
+
+	if numListed > api.bulkMaxItems {
+		log.Info(ctx, "invalid bulk delete - too many redirects listed", logData)
+		api.handleError(ctx, w, ErrTooManyItems, http.StatusBadRequest)
+		return
+	}
+
+	items := api.listedDeleteItems(request)
+	if request.Prefix != "" {
+		var err error
+		// without a host, a prefix only selects global redirects, as listed paths do, unless every host is asked for
+		filter := store.RedirectFilter{Host: request.Host, Prefix: request.Prefix, GlobalOnly: !request.AllHosts}
+		items, err = api.findDeleteItems(ctx, filter)
+		if err != nil {
+			if errors.Is(err, ErrTooManyItems) {
+				log.Info(ctx, "invalid bulk delete - the prefix selects too many redirects", logData)
+				api.handleError(ctx, w, ErrTooManyItems, http.StatusBadRequest)
+				return
+			}
+			log.Error(ctx, "redis failed on finding redirects to delete", err, logData)
+			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	report := models.DeleteReport{DryRun: dryRun, Items: []models.DeleteResult{}}
+	err := api.deleteItems(ctx, items, dryRun, &report)
+	extendDeadlines(ctx, w)
+	if err != nil {
+		logData["deleted"] = report.Deleted
+		log.Error(ctx, "redis failed on deleting redirects", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+	log.Info(ctx, "redirects deleted", log.Data{QueryParameterDryRun: dryRun, "deleted": report.Deleted, "not_found": report.NotFound, "rejected": report.Rejected})
+
+	reportResponse, err := json.Marshal(report)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaTypeJSON)
+	if _, err = w.Write(reportResponse); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+	}
+}
+
+// listedDeleteItems returns the redirects listed by id and then by path in a bulk delete, in the order they
+// are listed. Ids that cannot be decoded and paths that are invalid are returned with the reason.
+func (api *RedirectAPI) listedDeleteItems(request models.BulkDelete) []deleteItem {
+	items := make([]deleteItem, 0, len(request.IDs)+len(request.Paths))
+
+	for _, id := range request.IDs {
+		item := deleteItem{id: id}
+		key, err := models.ParseRedirectID(id)
+		if err != nil {
+			item.err = ErrInvalidBase64Id
+		} else {
+			item.key = api.canonicalKey(key)
+		}
+		items = append(items, item)
+	}
+
+	for _, path := range request.Paths {
+		var item deleteItem
+		if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, models.RegexAnchor) {
+			item.key = models.RedirectKey(request.Host, path)
+			item.err = ErrInvalidDeletePath
+		} else {
+			item.key = api.canonicalKey(models.RedirectKey(request.Host, path))
+		}
+		item.id = models.RedirectID(item.key)
+		items = append(items, item)
+	}
+
+	return items
+}
+
+// findDeleteItems returns every redirect selected by the filter. ErrTooManyItems is returned if the filter
+// selects more than the maximum number of redirects, so that nothing is deleted.
+func (api *RedirectAPI) findDeleteItems(ctx context.Context, filter store.RedirectFilter) ([]deleteItem, error) {
+	items := []deleteItem{}
+
+	err := api.RedirectStore.WalkRedirects(ctx, filter, func(redirect *models.Redirect) error {
+		if len(items) == api.bulkMaxItems {
+			return ErrTooManyItems
+		}
+
+		key := redirect.Key()
+		items = append(items, deleteItem{id: models.RedirectID(key), key: key})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// deleteItems deletes each of the given redirects in turn, adding the outcome for each to the report. In a dry
+// run, the redirects are only looked up. An error is only returned if the store fails, in which case the
+// redirects already deleted stay deleted.
+func (api *RedirectAPI) deleteItems(ctx context.Context, items []deleteItem, dryRun bool, report *models.DeleteReport) error {
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		result := models.DeleteResult{ID: item.id}
+		if item.key != "" {
+			result.Host, result.From = models.ParseRedirectKey(item.key)
+		}
+
+		err := item.err
+		if err == nil && seen[item.key] {
+			err = ErrDuplicateDeleteItem
+		}
+		seen[item.key] = true
+
+		switch {
+		case err != nil:
+			result.Result = models.DeleteResultRejected
+			result.Error = err.Error()
+		case dryRun:
+			result.Result = models.DeleteResultWouldDelete
+			if _, err := api.RedirectStore.GetRedirect(ctx, item.key); err != nil {
+				if !errors.Is(err, disRedis.ErrKeyNotFound) {
+					return err
+				}
+				result.Result = models.DeleteResultNotFound
+			}
+		default:
+			result.Result = models.DeleteResultDeleted
+			if err := api.RedirectStore.DeleteRedirect(ctx, item.key); err != nil {
+				if !errors.Is(err, disRedis.ErrKeyNotFound) {
+					return err
+				}
+				result.Result = models.DeleteResultNotFound
+			}
+		}
+
+		report.Add(result)
+	}
+
+	return nil
+}
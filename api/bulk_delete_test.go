@@ -0,0 +1,236 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/config"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const bulkDeleteURL = "http://localhost:29900/v1/bulk/delete"
+
+func TestDeleteRedirects(t *testing.T) {
+	Convey("Given a store with redirects", t, func() {
+		values := map[string]string{
+			"/economy/inflation":           "/business/inflation",
+			"/economy/gdp":                 "/business/gdp",
+			"//cy.ons.gov.uk/economy/jobs": "/business/jobs",
+			"/census":                      "/people",
+		}
+		mockStore := storetest.NewMapStorer(values)
+		dataStore := &store.Datastore{Backend: mockStore}
+		_, err := dataStore.RebuildIndexes(context.Background())
+		So(err, ShouldBeNil)
+		authMock := newAuthMiddlwareMock()
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		redirectAPI := api.Setup(context.Background(), mux.NewRouter(), dataStore, authMock, cfg)
+
+		deleteRedirects := func(redirectAPI *api.RedirectAPI, query, body string) (*httptest.ResponseRecorder, models.DeleteReport) {
+			request := httptest.NewRequest(http.MethodPost, bulkDeleteURL+query, strings.NewReader(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			var report models.DeleteReport
+			if responseRecorder.Code == http.StatusOK {
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &report), ShouldBeNil)
+			}
+			return responseRecorder, report
+		}
+
+		Convey("Then deleting redirects in bulk requires the delete permission", func() {
+			var permissions []string
+			for _, call := range authMock.RequireCalls() {
+				permissions = append(permissions, call.Permission)
+			}
+			So(permissions, ShouldContain, "redirects:delete")
+		})
+
+		Convey("When redirects are listed by id and path", func() {
+			body := `{
+				"ids": ["` + encodeBase64("/economy/gdp") + `", "not base64!", "` + encodeBase64("/missing") + `"],
+				"paths": ["/census", "/economy/gdp", "economy"]
+			}`
+			responseRecorder, report := deleteRedirects(redirectAPI, "", body)
+
+			Convey("Then each listed redirect is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(values, ShouldNotContainKey, "/economy/gdp")
+				So(values, ShouldNotContainKey, "/census")
+				landing, err := dataStore.GetRedirectsTo(context.Background(), "/people")
+				So(err, ShouldBeNil)
+				So(landing, ShouldBeEmpty)
+				So(values, ShouldContainKey, "/economy/inflation")
+			})
+
+			Convey("And the outcome of each is reported", func() {
+				So(report.DryRun, ShouldBeFalse)
+				So(report.Count, ShouldEqual, 6)
+				So(report.Deleted, ShouldEqual, 2)
+				So(report.NotFound, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 3)
+
+				So(report.Items[0].Result, ShouldEqual, models.DeleteResultDeleted)
+				So(report.Items[0].From, ShouldEqual, "/economy/gdp")
+				So(report.Items[1].Result, ShouldEqual, models.DeleteResultRejected)
+				So(report.Items[1].Error, ShouldEqual, api.ErrInvalidBase64Id.Error())
+				So(report.Items[2].Result, ShouldEqual, models.DeleteResultNotFound)
+				So(report.Items[3].Result, ShouldEqual, models.DeleteResultDeleted)
+				So(report.Items[3].ID, ShouldEqual, encodeBase64("/census"))
+				So(report.Items[4].Error, ShouldEqual, api.ErrDuplicateDeleteItem.Error())
+				So(report.Items[5].Error, ShouldEqual, api.ErrInvalidDeletePath.Error())
+			})
+		})
+
+		Convey("When redirects are listed by path on a host", func() {
+			_, report := deleteRedirects(redirectAPI, "", `{"host": "cy.ons.gov.uk", "paths": ["/economy/jobs"]}`)
+
+			Convey("Then the redirect scoped to the host is deleted", func() {
+				So(report.Deleted, ShouldEqual, 1)
+				So(report.Items[0].Host, ShouldEqual, "cy.ons.gov.uk")
+				So(values, ShouldNotContainKey, "//cy.ons.gov.uk/economy/jobs")
+			})
+		})
+
+		Convey("When redirects are selected by a prefix", func() {
+			responseRecorder, report := deleteRedirects(redirectAPI, "", `{"prefix": "/economy/"}`)
+
+			Convey("Then every global redirect starting with the prefix is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(report.Deleted, ShouldEqual, 2)
+				So(values, ShouldNotContainKey, "/economy/gdp")
+				So(values, ShouldNotContainKey, "/economy/inflation")
+				So(values, ShouldContainKey, "/census")
+			})
+
+			Convey("And the host scoped redirects starting with the prefix are left alone", func() {
+				So(values, ShouldContainKey, "//cy.ons.gov.uk/economy/jobs")
+			})
+		})
+
+		Convey("When redirects on every host are selected by a prefix", func() {
+			responseRecorder, report := deleteRedirects(redirectAPI, "", `{"prefix": "/economy/", "all_hosts": true}`)
+
+			Convey("Then every global and host scoped redirect starting with the prefix is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(report.Deleted, ShouldEqual, 3)
+				So(values, ShouldNotContainKey, "/economy/gdp")
+				So(values, ShouldNotContainKey, "/economy/inflation")
+				So(values, ShouldNotContainKey, "//cy.ons.gov.uk/economy/jobs")
+				So(values, ShouldContainKey, "/census")
+			})
+		})
+
+		Convey("When every host is asked for along with a host", func() {
+			responseRecorder, _ := deleteRedirects(redirectAPI, "", `{"prefix": "/economy/", "host": "cy.ons.gov.uk", "all_hosts": true}`)
+
+			Convey("Then nothing is deleted and the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, models.ErrorCodeInvalidAllHosts)
+				So(values, ShouldContainKey, "//cy.ons.gov.uk/economy/jobs")
+			})
+		})
+
+		Convey("When redirects are selected by a prefix in a dry run", func() {
+			responseRecorder, report := deleteRedirects(redirectAPI, "?dry_run=true", `{"prefix": "/economy/", "host": "cy.ons.gov.uk"}`)
+
+			Convey("Then the redirects that would be deleted are reported", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(report.DryRun, ShouldBeTrue)
+				So(report.Deleted, ShouldEqual, 1)
+				So(report.Items[0].Result, ShouldEqual, models.DeleteResultWouldDelete)
+				So(report.Items[0].From, ShouldEqual, "/economy/jobs")
+			})
+
+			Convey("And nothing is deleted", func() {
+				So(mockStore.DeleteValueCalls(), ShouldBeEmpty)
+				So(values, ShouldHaveLength, 4)
+			})
+		})
+
+		Convey("When redirects are listed in a dry run", func() {
+			_, report := deleteRedirects(redirectAPI, "?dry_run=true", `{"paths": ["/census", "/missing"]}`)
+
+			Convey("Then the redirects that do not exist are reported as not found", func() {
+				So(report.Items[0].Result, ShouldEqual, models.DeleteResultWouldDelete)
+				So(report.Items[1].Result, ShouldEqual, models.DeleteResultNotFound)
+				So(mockStore.DeleteValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a prefix selects more redirects than the maximum", func() {
+			limitedCfg := *cfg
+			limitedCfg.BulkMaxItems = 2
+			limitedAPI := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &limitedCfg)
+
+			responseRecorder, _ := deleteRedirects(limitedAPI, "", `{"prefix": "/economy/", "all_hosts": true}`)
+
+			Convey("Then nothing is deleted and the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrTooManyItems.Error())
+				So(mockStore.DeleteValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the body is larger than the maximum size", func() {
+			limitedCfg := *cfg
+			limitedCfg.BulkMaxBodySize = 16
+			limitedAPI := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &limitedCfg)
+
+			responseRecorder, _ := deleteRedirects(limitedAPI, "", `{"paths": ["/census", "/economy"]}`)
+
+			Convey("Then nothing is deleted and the response status code should be 413", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, models.ErrorCodeRequestBodyTooLarge)
+				So(mockStore.DeleteValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When both a list and a prefix are provided", func() {
+			responseRecorder, _ := deleteRedirects(redirectAPI, "", `{"paths": ["/census"], "prefix": "/economy/"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidBulkDelete.Error())
+			})
+		})
+
+		Convey("When neither a list nor a prefix is provided", func() {
+			responseRecorder, _ := deleteRedirects(redirectAPI, "", `{}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidBulkDelete.Error())
+			})
+		})
+
+		Convey("When the prefix does not start with '/'", func() {
+			responseRecorder, _ := deleteRedirects(redirectAPI, "", `{"prefix": "economy"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidPrefix.Error())
+			})
+		})
+
+		Convey("When the dry run value is not a boolean", func() {
+			responseRecorder, _ := deleteRedirects(redirectAPI, "?dry_run=maybe", `{"prefix": "/economy/"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidDryRun.Error())
+				So(mockStore.DeleteValueCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
@@ -0,0 +1,314 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/config"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const importURL = "http://localhost:29900/v1/bulk/import"
+
+func TestImportRedirects(t *testing.T) {
+	Convey("Given a store with an existing redirect", t, func() {
+		values := map[string]string{
+			"/existing": `{"to":"/old-target","created_at":"2025-01-02T09:30:00Z","created_by":"creator@ons.gov.uk"}`,
+		}
+		mockStore := storetest.NewMapStorer(values)
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		importRedirects := func(redirectAPI *api.RedirectAPI, query, contentType, body string) (*httptest.ResponseRecorder, models.ImportReport) {
+			request := httptest.NewRequest(http.MethodPost, importURL+query, strings.NewReader(body))
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			if contentType != "" {
+				request.Header.Set("Content-Type", contentType)
+			}
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			var report models.ImportReport
+			if strings.HasPrefix(responseRecorder.Header().Get("Content-Type"), "application/json") {
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &report), ShouldBeNil)
+			}
+			return responseRecorder, report
+		}
+
+		Convey("When redirects are imported from a JSON array", func() {
+			responseRecorder, report := importRedirects(redirectAPI, "", "application/json", `[
+				{"from": "/new", "to": "/new-target", "status_code": 302},
+				{"from": "/existing", "to": "/new-target"},
+				{"from": "/invalid", "to": "invalid"},
+				{"from": "/new", "to": "/other-target"},
+				{"from": 1}
+			]`)
+
+			Convey("Then each valid redirect is created or updated", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/new"), ShouldEqual, "/new-target")
+				So(storedTarget(values, "/existing"), ShouldEqual, "/new-target")
+				So(values, ShouldNotContainKey, "/invalid")
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/existing"]), &stored), ShouldBeNil)
+				So(stored.CreatedBy, ShouldEqual, "creator@ons.gov.uk")
+				So(stored.UpdatedBy, ShouldEqual, testUserID)
+			})
+
+			Convey("And the outcome of each row is reported", func() {
+				So(report.Count, ShouldEqual, 5)
+				So(report.Created, ShouldEqual, 1)
+				So(report.Updated, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 3)
+
+				So(report.Items[0].Row, ShouldEqual, 1)
+				So(report.Items[0].Result, ShouldEqual, models.ImportResultCreated)
+				So(report.Items[0].ID, ShouldEqual, encodeBase64("/new"))
+				So(report.Items[1].Result, ShouldEqual, models.ImportResultUpdated)
+				So(report.Items[2].Result, ShouldEqual, models.ImportResultRejected)
+				So(report.Items[2].Error, ShouldEqual, api.ErrFromToNotRelative.Error())
+				So(report.Items[3].Result, ShouldEqual, models.ImportResultRejected)
+				So(report.Items[3].Error, ShouldContainSubstring, api.ErrDuplicateImportRow.Error())
+				So(report.Items[3].Error, ShouldEndWith, "row 1")
+				So(report.Items[4].Result, ShouldEqual, models.ImportResultRejected)
+				So(report.Items[4].Error, ShouldEqual, api.ErrInvalidRequestBody.Error())
+			})
+		})
+
+		Convey("When redirects are imported from a CSV file", func() {
+			responseRecorder, report := importRedirects(redirectAPI, "", "text/csv; charset=utf-8", strings.Join([]string{
+				"from,to,status_code,host,valid_until",
+				"/csv,/csv-target,307,,",
+				"/csv-host,/csv-target,,cy.ons.gov.uk,2099-01-01T00:00:00Z",
+				"/bad-status,/csv-target,permanent,,",
+				"/missing-column,/csv-target",
+			}, "\n"))
+
+			Convey("Then each valid redirect is created", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/csv"]), &stored), ShouldBeNil)
+				So(stored.To, ShouldEqual, "/csv-target")
+				So(stored.StatusCode, ShouldEqual, http.StatusTemporaryRedirect)
+
+				So(json.Unmarshal([]byte(values["//cy.ons.gov.uk/csv-host"]), &stored), ShouldBeNil)
+				So(stored.ValidUntil, ShouldNotBeNil)
+			})
+
+			Convey("And rows that cannot be read are rejected", func() {
+				So(report.Created, ShouldEqual, 2)
+				So(report.Rejected, ShouldEqual, 2)
+				So(report.Items[1].Host, ShouldEqual, "cy.ons.gov.uk")
+				So(report.Items[2].Error, ShouldEqual, api.ErrInvalidImportValue.Error()+": status_code")
+				So(report.Items[3].Error, ShouldEqual, api.ErrInvalidImportRow.Error())
+			})
+		})
+
+		Convey("When a CSV file has a column that is not a field of a redirect", func() {
+			responseRecorder, _ := importRedirects(redirectAPI, "", "text/csv", "from,to,notes\n/a,/b,moved")
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidImportColumns.Error())
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the content type is not JSON or CSV", func() {
+			responseRecorder, _ := importRedirects(redirectAPI, "", "application/xml", "<redirects/>")
+
+			Convey("Then the response status code should be 415", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+		})
+
+		Convey("When the body is not a JSON array", func() {
+			responseRecorder, _ := importRedirects(redirectAPI, "", "", `{"from": "/a", "to": "/b"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidRequestBody.Error())
+			})
+		})
+
+		Convey("When more redirects are imported than the maximum", func() {
+			cfg, err := config.Get()
+			So(err, ShouldBeNil)
+			limitedCfg := *cfg
+			limitedCfg.BulkMaxItems = 1
+			limitedAPI := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &limitedCfg)
+
+			responseRecorder, _ := importRedirects(limitedAPI, "", "", `[{"from": "/a", "to": "/b"}, {"from": "/c", "to": "/d"}]`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrTooManyItems.Error())
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+			})
+
+			Convey("And the rest of the body is not read", func() {
+				responseRecorder, _ := importRedirects(limitedAPI, "", "", `[{"from": "/a", "to": "/b"}, {"from": "/c", "to": "/d"}, not json`)
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrTooManyItems.Error())
+			})
+
+			Convey("And the same limit applies to a CSV file", func() {
+				responseRecorder, _ := importRedirects(limitedAPI, "", "text/csv", "from,to\n/a,/b\n/c,/d\n\"unterminated")
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrTooManyItems.Error())
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the body is larger than the maximum size", func() {
+			cfg, err := config.Get()
+			So(err, ShouldBeNil)
+			limitedCfg := *cfg
+			limitedCfg.BulkMaxBodySize = 32
+			limitedAPI := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &limitedCfg)
+
+			responseRecorder, _ := importRedirects(limitedAPI, "", "", `[{"from": "/a", "to": "/b"}, {"from": "/c", "to": "/d"}]`)
+
+			Convey("Then the response status code should be 413", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusRequestEntityTooLarge)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, models.ErrorCodeRequestBodyTooLarge)
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When redirects including an invalid one are imported atomically", func() {
+			responseRecorder, report := importRedirects(redirectAPI, "?atomic=true", "", `[
+				{"from": "/new", "to": "/new-target"},
+				{"from": "/invalid", "to": "/invalid"}
+			]`)
+
+			Convey("Then none of the redirects are imported", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 1)
+				So(report.Items[0].Result, ShouldEqual, models.ImportResultSkipped)
+				So(report.Items[1].Error, ShouldEqual, api.ErrCircularPaths.Error())
+			})
+		})
+
+		Convey("When redirects that form a loop between themselves are imported atomically", func() {
+			responseRecorder, report := importRedirects(redirectAPI, "?atomic=true", "", `[
+				{"from": "/existing", "to": "/loop"},
+				{"from": "/loop-start", "to": "/loop"},
+				{"from": "/loop", "to": "/loop-start"}
+			]`)
+
+			Convey("Then the redirects already imported are put back as they were", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(storedTarget(values, "/existing"), ShouldEqual, "/old-target")
+				So(values, ShouldNotContainKey, "/loop-start")
+				So(values, ShouldNotContainKey, "/loop")
+				dataStore := store.Datastore{Backend: mockStore}
+				landing, err := dataStore.GetRedirectsTo(context.Background(), "/loop")
+				So(err, ShouldBeNil)
+				So(landing, ShouldBeEmpty)
+			})
+
+			Convey("And the row creating the loop is reported", func() {
+				So(report.Skipped, ShouldEqual, 2)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Items[2].Error, ShouldEqual, api.ErrRedirectLoop.Error())
+			})
+		})
+
+		Convey("When redirects are imported atomically and one is changed by another request before they are put back", func() {
+			compareAndDelete := mockStore.CompareAndDeleteValueFunc
+			mockStore.CompareAndDeleteValueFunc = func(ctx context.Context, key, expected string) error {
+				if key == "/loop-start" {
+					values[key] = `{"to":"/elsewhere"}`
+				}
+				return compareAndDelete(ctx, key, expected)
+			}
+			responseRecorder, report := importRedirects(redirectAPI, "?atomic=true", "", `[
+				{"from": "/existing", "to": "/loop"},
+				{"from": "/loop-start", "to": "/loop"},
+				{"from": "/loop", "to": "/loop-start"}
+			]`)
+
+			Convey("Then the changed redirect is left as the other request saved it", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(storedTarget(values, "/loop-start"), ShouldEqual, "/elsewhere")
+			})
+
+			Convey("And the other redirects are still put back", func() {
+				So(storedTarget(values, "/existing"), ShouldEqual, "/old-target")
+				So(values, ShouldNotContainKey, "/loop")
+			})
+
+			Convey("And the row that could not be rolled back is reported", func() {
+				So(report.Created, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Items[0].Result, ShouldEqual, models.ImportResultSkipped)
+				So(report.Items[1].Result, ShouldEqual, models.ImportResultCreated)
+				So(report.Items[1].Warning, ShouldEqual, api.ErrImportNotRolledBack.Error())
+			})
+		})
+
+		Convey("When the store fails while redirects are being imported", func() {
+			setValueIfAbsent := mockStore.SetValueIfAbsentFunc
+			mockStore.SetValueIfAbsentFunc = func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				if key == "/failing" {
+					return false, errors.New("redis is unavailable")
+				}
+				return setValueIfAbsent(ctx, key, value, expiration)
+			}
+			rows := `[
+				{"from": "/existing", "to": "/new-target"},
+				{"from": "/failing", "to": "/new-target"},
+				{"from": "/not-attempted", "to": "/new-target"}
+			]`
+
+			Convey("Then the outcome of each row so far is reported with a 500 status", func() {
+				responseRecorder, report := importRedirects(redirectAPI, "", "", rows)
+				So(responseRecorder.Code, ShouldEqual, http.StatusInternalServerError)
+				So(storedTarget(values, "/existing"), ShouldEqual, "/new-target")
+				So(values, ShouldNotContainKey, "/not-attempted")
+
+				So(report.Updated, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 1)
+				So(report.Items[1].Error, ShouldEqual, api.ErrInternal.Error())
+				So(report.Items[2].Result, ShouldEqual, models.ImportResultSkipped)
+			})
+
+			Convey("Then in atomic mode the rows already saved are put back first", func() {
+				responseRecorder, report := importRedirects(redirectAPI, "?atomic=true", "", rows)
+				So(responseRecorder.Code, ShouldEqual, http.StatusInternalServerError)
+				So(storedTarget(values, "/existing"), ShouldEqual, "/old-target")
+				So(values, ShouldNotContainKey, "/not-attempted")
+
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 2)
+				So(report.Items[0].Result, ShouldEqual, models.ImportResultSkipped)
+			})
+		})
+
+		Convey("When the atomic value is not a boolean", func() {
+			responseRecorder, _ := importRedirects(redirectAPI, "?atomic=maybe", "", `[]`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidAtomic.Error())
+			})
+		})
+	})
+}
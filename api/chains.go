@@ -0,0 +1,395 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// chainWarning is the Warning header value returned when a redirect is accepted despite creating a chain
+// longer than the maximum depth
+const chainWarning = `199 - "the redirect creates a chain of redirects longer than the maximum depth"`
+
+// collapseWarning is the Warning header value returned when a redirect is saved but the chains leading to it could
+// not be collapsed
+const collapseWarning = `199 - "the redirect was saved but the chains of redirects leading to it could not be collapsed"`
+
+// maxRetargetAttempts is the number of times a redirect is read and written again when pointing it at the end of
+// its chain, before giving up because it keeps being changed at the same time
+const maxRetargetAttempts = 3
+
+// getChainDepth follows the redirects that would be applied from the target of the given redirect, matching each
+// hop in the same way as resolving a path, and returns the number of hops a visitor to its 'from' path would be
+// sent through. ErrRedirectLoop is returned if the chain leads back to a path that has already been visited.
+// Only the chains of exact redirects are followed, as prefix and regex redirects do not have a single path to
+// continue from, and the chain is not followed further than it could be resolved.
+func (api *RedirectAPI) getChainDepth(ctx context.Context, redirect *models.Redirect, now time.Time) (int, error) {
+	depth := 1
+	if redirect.Type != models.RedirectTypeExact {
+		return depth, nil
+	}
+
+	// the redirect being written is not stored yet, so a chain leading back to it has to be spotted by path
+	visited := map[string]bool{redirect.From: true}
+	maxDepth := max(api.chainMaxDepth, api.resolveMaxHops)
+	next := redirect.To
+	for isValidRelativePath(next) && depth <= maxDepth {
+		path, rawQuery := splitTarget(next)
+		path = api.canonicalPath(path)
+
+		if visited[path] {
+			return 0, ErrRedirectLoop
+		}
+		visited[path] = true
+
+		existing, err := api.RedirectStore.MatchRedirect(ctx, redirect.Host, path, now)
+		if err != nil {
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				break
+			}
+			return 0, err
+		}
+
+		depth++
+		next = existing.ResolveTarget(path, rawQuery)
+	}
+
+	return depth, nil
+}
+
+// getUpstreamDepth follows the exact redirects that lead to the 'from' path of the given redirect back through the
+// reverse index, and returns the number of hops in the longest chain of them, so that a redirect added to the end
+// of a chain is counted as part of it. Redirects scoped to a different host are not followed, as they do not lead
+// to a host scoped redirect, and chains are not followed further than they could be resolved.
+func (api *RedirectAPI) getUpstreamDepth(ctx context.Context, redirect *models.Redirect, now time.Time) (int, error) {
+	if redirect.Type != models.RedirectTypeExact {
+		return 0, nil
+	}
+
+	visited := map[string]bool{redirect.Key(): true}
+	return api.followUpstream(ctx, redirect.Host, redirect.From, visited, max(api.chainMaxDepth, api.resolveMaxHops), now)
+}
+
+// followUpstream returns the number of hops in the longest chain of active exact redirects leading to the given
+// path, up to the given limit. The redirects already on the chain are skipped, so that a loop is not followed.
+func (api *RedirectAPI) followUpstream(ctx context.Context, host, path string, visited map[string]bool, limit int, now time.Time) (int, error) {
+	if limit == 0 {
+		return 0, nil
+	}
+
+	landing, err := api.RedirectStore.GetRedirectsTo(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+
+	depth := 0
+	for i := range landing {
+		existing := &landing[i]
+		if existing.Type != models.RedirectTypeExact ||
+			!existing.IsActive(now) ||
+			visited[existing.Key()] ||
+			(host != "" && existing.Host != host) ||
+			api.canonicalPath(models.TargetPath(existing.To)) != path {
+			continue
+		}
+
+		visited[existing.Key()] = true
+		upstream, err := api.followUpstream(ctx, existing.Host, existing.From, visited, limit-1, now)
+		delete(visited, existing.Key())
+		if err != nil {
+			return 0, err
+		}
+		depth = max(depth, upstream+1)
+	}
+
+	return depth, nil
+}
+
+// getChainRedirect gets the redirect that applies to the given path next in a chain, trying the redirect scoped
+// to the host before the global redirect
+func (api *RedirectAPI) getChainRedirect(ctx context.Context, host, path string) (*models.Redirect, error) {
+	if host != "" {
+		redirect, err := api.RedirectStore.GetRedirect(ctx, models.RedirectKey(host, path))
+		if err == nil || !errors.Is(err, disRedis.ErrKeyNotFound) {
+			return redirect, err
+		}
+	}
+
+	return api.RedirectStore.GetRedirect(ctx, path)
+}
+
+// getChainTarget follows the active exact redirects from the given target and returns the target at the end
+// of the chain, which is the given target itself when no redirect applies to it. The chain is only followed
+// through permanent redirects, as a temporary or time limited hop may send visitors somewhere else later.
+func (api *RedirectAPI) getChainTarget(ctx context.Context, host, target string, now time.Time) (string, error) {
+	visited := map[string]bool{}
+	for isValidRelativePath(target) {
+		existing, err := api.getChainRedirect(ctx, host, api.canonicalPath(models.TargetPath(target)))
+		if err != nil {
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				break
+			}
+			return "", err
+		}
+
+		if existing.Type != models.RedirectTypeExact || !existing.IsActive(now) || !existing.IsPermanent() {
+			break
+		}
+
+		if visited[existing.Key()] {
+			return "", ErrRedirectLoop
+		}
+		visited[existing.Key()] = true
+
+		target = existing.To
+	}
+
+	return target, nil
+}
+
+// collapseChainsTo rewrites the exact redirects that point at the 'from' path of the given redirect so that
+// they point straight at the end of its chain instead, and returns the redirects it changed. The redirects are
+// found using the reverse index, so only those whose target is stored in the same form as the 'from' path are
+// changed. Redirects scoped to a different host are left alone, as they do not lead to a host scoped redirect,
+// as are all of them when the given redirect is not permanent.
+func (api *RedirectAPI) collapseChainsTo(ctx context.Context, redirect *models.Redirect, identity string, now time.Time) ([]models.Redirect, error) {
+	if redirect.Type != models.RedirectTypeExact || !redirect.IsActive(now) || !redirect.IsPermanent() {
+		return []models.Redirect{}, nil
+	}
+
+	target, err := api.getChainTarget(ctx, redirect.Host, redirect.To, now)
+	if err != nil {
+		return nil, err
+	}
+
+	landing, err := api.RedirectStore.GetRedirectsTo(ctx, redirect.From)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointing []*models.Redirect
+	for i := range landing {
+		existing := &landing[i]
+		if existing.Type == models.RedirectTypeExact &&
+			existing.Key() != redirect.Key() &&
+			(redirect.Host == "" || existing.Host == redirect.Host) &&
+			api.canonicalPath(models.TargetPath(existing.To)) == redirect.From {
+			pointing = append(pointing, existing)
+		}
+	}
+
+	return api.retargetRedirects(ctx, pointing, target, identity, now)
+}
+
+// collapseAllChains rewrites every active exact redirect that leads to another exact redirect so that it points
+// straight at the end of its chain, and returns the redirects it changed. Redirects that lead into a loop are
+// left alone.
+func (api *RedirectAPI) collapseAllChains(ctx context.Context, identity string, now time.Time) ([]models.Redirect, error) {
+	var candidates []*models.Redirect
+	err := api.RedirectStore.WalkRedirects(ctx, store.RedirectFilter{}, func(existing *models.Redirect) error {
+		if existing.Type == models.RedirectTypeExact && existing.IsActive(now) && isValidRelativePath(existing.To) {
+			candidates = append(candidates, existing)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	collapsed := []models.Redirect{}
+	for _, candidate := range candidates {
+		target, err := api.getChainTarget(ctx, candidate.Host, candidate.To, now)
+		if err != nil {
+			if errors.Is(err, ErrRedirectLoop) {
+				log.Warn(ctx, "redirect leads into a loop so its chain cannot be collapsed", log.Data{
+					models.LogRedirectHostKey: candidate.Host,
+					models.LogRedirectFromKey: candidate.From,
+				})
+				continue
+			}
+			return nil, err
+		}
+
+		retargeted, err := api.retargetRedirects(ctx, []*models.Redirect{candidate}, target, identity, now)
+		if err != nil {
+			return nil, err
+		}
+		collapsed = append(collapsed, retargeted...)
+	}
+
+	return collapsed, nil
+}
+
+// retargetRedirects points each of the given redirects at the given target, keeping their expiry times and any
+// query string or fragment they have, and returns the redirects that were changed. Redirects that already point
+// at the target, that would be pointed at their own path or that have expired are left alone, as are those that
+// are changed by someone else in the meantime.
+func (api *RedirectAPI) retargetRedirects(ctx context.Context, redirects []*models.Redirect, target, identity string, now time.Time) ([]models.Redirect, error) {
+	retargeted := []models.Redirect{}
+	for _, redirect := range redirects {
+		changed, err := api.retargetRedirect(ctx, redirect, target, identity, now)
+		if err != nil {
+			if errors.Is(err, store.ErrValueChanged) {
+				log.Warn(ctx, "redirect kept being changed so it was not pointed at the end of its chain", log.Data{
+					models.LogRedirectHostKey: redirect.Host,
+					models.LogRedirectFromKey: redirect.From,
+				})
+				continue
+			}
+			return nil, err
+		}
+
+		if changed != nil {
+			retargeted = append(retargeted, *changed)
+		}
+	}
+
+	return retargeted, nil
+}
+
+// retargetRedirect points the given redirect at the given target, reading it again so that it is only changed
+// if it has not been changed since. A redirect that has been pointed somewhere else or deleted is left alone,
+// and nil is returned. store.ErrValueChanged is returned if it is still being changed after retrying.
+func (api *RedirectAPI) retargetRedirect(ctx context.Context, redirect *models.Redirect, target, identity string, now time.Time) (*models.Redirect, error) {
+	for attempt := 0; attempt < maxRetargetAttempts; attempt++ {
+		current, version, err := api.RedirectStore.GetRedirectWithVersion(ctx, redirect.Key())
+		if err != nil {
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		if current.To != redirect.To || current.To == target {
+			return nil, nil
+		}
+
+		to := keepQuery(current.To, target)
+		if current.To == to || api.canonicalPath(models.TargetPath(target)) == current.From {
+			return nil, nil
+		}
+
+		var expiration time.Duration
+		if current.ExpiresAt != nil {
+			expiration = current.ExpiresAt.Sub(now)
+			if expiration <= 0 {
+				return nil, nil
+			}
+		}
+
+		current.To = to
+		current.UpdatedAt = &now
+		current.UpdatedBy = identity
+		err = api.RedirectStore.UpdateRedirect(ctx, current, version, expiration)
+		if err == nil {
+			return current, nil
+		}
+		if !errors.Is(err, store.ErrValueChanged) {
+			return nil, err
+		}
+	}
+
+	return nil, store.ErrValueChanged
+}
+
+// keepQuery returns the given target with the query string and fragment of the given 'to' path added to it, so
+// that a redirect pointed somewhere else still passes on the parameters it was created with
+func keepQuery(to, target string) string {
+	suffix := strings.TrimPrefix(to, models.TargetPath(to))
+	if suffix == "" {
+		return target
+	}
+
+	query, fragment, hasFragment := strings.Cut(suffix, "#")
+	query = strings.TrimPrefix(query, "?")
+
+	path, targetFragment, hasTargetFragment := strings.Cut(target, "#")
+	if query != "" {
+		if strings.Contains(path, "?") {
+			path += "&" + query
+		} else {
+			path += "?" + query
+		}
+	}
+
+	switch {
+	case hasFragment:
+		return path + "#" + fragment
+	case hasTargetFragment:
+		return path + "#" + targetFragment
+	default:
+		return path
+	}
+}
+
+// collapseChains rewrites every redirect that leads to another redirect so that it points straight at the end
+// of its chain, reporting every redirect that was changed
+func (api *RedirectAPI) collapseChains(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	identity, err := api.getCallerIdentity(r)
+	if err != nil {
+		log.Error(ctx, "failed to get the identity of the caller", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	collapsed, err := api.collapseAllChains(ctx, identity, time.Now().UTC())
+	extendDeadlines(ctx, w)
+	if err != nil {
+		log.Error(ctx, "redis failed on collapsing redirect chains", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info(ctx, "redirect chains collapsed", log.Data{"num_redirects": len(collapsed)})
+	api.writeCollapsedRedirects(w, r, http.StatusOK, collapsed)
+}
+
+// writeCollapsedRedirects writes the report of the redirects that were changed by collapsing chains
+func (api *RedirectAPI) writeCollapsedRedirects(w http.ResponseWriter, r *http.Request, status int, collapsed []models.Redirect) {
+	ctx := r.Context()
+
+	report, err := api.collapsedReport(r, collapsed)
+	if err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	collapsedResponse, err := json.Marshal(report)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(collapsedResponse); err != nil {
+		log.Error(ctx, "failed to write response", err)
+	}
+}
+
+// collapsedReport builds the report of the redirects that were changed by collapsing chains, setting the id,
+// links, remaining TTL and activation status of each
+func (api *RedirectAPI) collapsedReport(r *http.Request, collapsed []models.Redirect) (*models.CollapsedRedirects, error) {
+	for i := range collapsed {
+		if err := api.setResponseFields(r, &collapsed[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.CollapsedRedirects{
+		Count: len(collapsed),
+		Items: collapsed,
+	}, nil
+}
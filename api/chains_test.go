@@ -0,0 +1,315 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func storedTarget(values map[string]string, key string) string {
+	var stored models.Redirect
+	if err := json.Unmarshal([]byte(values[key]), &stored); err != nil {
+		return values[key]
+	}
+	return stored.To
+}
+
+func TestUpsertRedirectCollapsingChains(t *testing.T) {
+	Convey("Given redirects that point at a path", t, func() {
+		values := map[string]string{
+			"/a":                "/b",
+			"/z":                "/b",
+			"/query":            "/b?edition=2024",
+			"/c":                "/d",
+			"//cy.ons.gov.uk/a": "/b",
+		}
+		mockStore := storetest.NewMapStorer(values)
+		dataStore := store.Datastore{Backend: mockStore}
+		_, err := dataStore.RebuildIndexes(context.Background())
+		So(err, ShouldBeNil)
+		scans := len(mockStore.GetKeyValuePairsCalls())
+		redirectAPI := GetRedirectAPIWithMocks(dataStore)
+
+		put := func(url string, redirect models.Redirect) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(redirect)
+			request := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+		id := base64.URLEncoding.EncodeToString([]byte("/b"))
+
+		Convey("When a redirect from that path is added with chains collapsed", func() {
+			responseRecorder := put(getRedirectBaseURL+id+"?collapse_chains=true", models.Redirect{From: "/b", To: "/c"})
+
+			Convey("Then the redirects pointing at the path are pointed at the end of the chain", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(storedTarget(values, "/b"), ShouldEqual, "/c")
+				So(storedTarget(values, "/a"), ShouldEqual, "/d")
+				So(storedTarget(values, "/z"), ShouldEqual, "/d")
+				So(storedTarget(values, "//cy.ons.gov.uk/a"), ShouldEqual, "/d")
+			})
+
+			Convey("And they are found from the reverse index without scanning the store", func() {
+				So(mockStore.GetKeyValuePairsCalls(), ShouldHaveLength, scans)
+			})
+
+			Convey("And redirects to the path with a query string keep their query string", func() {
+				So(storedTarget(values, "/query"), ShouldEqual, "/d?edition=2024")
+			})
+
+			Convey("And the stored redirect is returned along with every changed redirect", func() {
+				var response models.CollapsingRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.ID, ShouldEqual, id)
+				So(response.To, ShouldEqual, "/c")
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldEqual, response.ETag())
+				So(response.Collapsed, ShouldNotBeNil)
+				So(response.Collapsed.Count, ShouldEqual, 4)
+
+				froms := []string{}
+				for _, redirect := range response.Collapsed.Items {
+					So(models.TargetPath(redirect.To), ShouldEqual, "/d")
+					So(redirect.ID, ShouldEqual, encodeBase64(redirect.Key()))
+					So(redirect.UpdatedBy, ShouldBeEmpty)
+					froms = append(froms, redirect.Key())
+				}
+				So(froms, ShouldContain, "/a")
+				So(froms, ShouldContain, "/z")
+				So(froms, ShouldContain, "/query")
+				So(froms, ShouldContain, "//cy.ons.gov.uk/a")
+			})
+		})
+
+		Convey("When a redirect from that path is added with chains collapsed but they cannot be collapsed", func() {
+			compareAndSwap := mockStore.CompareAndSwapValueFunc
+			mockStore.CompareAndSwapValueFunc = func(ctx context.Context, key, expected string, value interface{}, expiration time.Duration) error {
+				if key == "/a" {
+					return errors.New("redis is unavailable")
+				}
+				return compareAndSwap(ctx, key, expected, value, expiration)
+			}
+			responseRecorder := put(getRedirectBaseURL+id+"?collapse_chains=true", models.Redirect{From: "/b", To: "/c"})
+
+			Convey("Then the saved redirect is still returned, with a warning and no report", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(storedTarget(values, "/b"), ShouldEqual, "/c")
+				So(responseRecorder.Header().Get("Location"), ShouldNotBeEmpty)
+				So(responseRecorder.Header().Get("Warning"), ShouldContainSubstring, "could not be collapsed")
+
+				var response models.CollapsingRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.To, ShouldEqual, "/c")
+				So(response.Collapsed, ShouldBeNil)
+			})
+		})
+
+		Convey("When a host scoped redirect from that path is added with chains collapsed", func() {
+			hostID := base64.URLEncoding.EncodeToString([]byte("//cy.ons.gov.uk/b"))
+			responseRecorder := put(getRedirectBaseURL+hostID+"?collapse_chains=true", models.Redirect{Host: "cy.ons.gov.uk", From: "/b", To: "/c"})
+
+			Convey("Then only redirects scoped to the same host are changed", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(storedTarget(values, "//cy.ons.gov.uk/a"), ShouldEqual, "/d")
+				So(storedTarget(values, "/a"), ShouldEqual, "/b")
+			})
+		})
+
+		Convey("When a temporary redirect from that path is added with chains collapsed", func() {
+			responseRecorder := put(getRedirectBaseURL+id+"?collapse_chains=true", models.Redirect{From: "/b", To: "/c", StatusCode: http.StatusFound})
+
+			Convey("Then the redirects pointing at the path are left alone", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(storedTarget(values, "/a"), ShouldEqual, "/b")
+				So(storedTarget(values, "/query"), ShouldEqual, "/b?edition=2024")
+			})
+		})
+
+		Convey("When a redirect from that path is added without collapsing chains", func() {
+			responseRecorder := put(getRedirectBaseURL+id, models.Redirect{From: "/b", To: "/c"})
+
+			Convey("Then the redirects pointing at the path are left alone", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(responseRecorder.Body.String(), ShouldNotContainSubstring, `"items"`)
+				So(storedTarget(values, "/a"), ShouldEqual, "/b")
+				So(storedTarget(values, "/query"), ShouldEqual, "/b?edition=2024")
+			})
+		})
+
+		Convey("When the collapse_chains value is not a boolean", func() {
+			responseRecorder := put(getRedirectBaseURL+id+"?collapse_chains=sometimes", models.Redirect{From: "/b", To: "/c"})
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidCollapseChains.Error())
+				So(mockStore.SetValueCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestCollapseChains(t *testing.T) {
+	Convey("Given a store containing chains of redirects", t, func() {
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		values := map[string]string{
+			"/a":         "/b",
+			"/b":         "/c",
+			"/c":         "/d",
+			"/d":         "/e",
+			"/loop1":     "/loop2",
+			"/loop2":     "/loop1",
+			"/scheduled": `{"to":"/a","valid_from":"` + future + `"}`,
+			"/later":     "/scheduled",
+			"/economy/*": `{"to":"/b/*","type":"prefix"}`,
+		}
+		mockStore := storetest.NewMapStorer(values)
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the chains are collapsed", func() {
+			request := httptest.NewRequest(http.MethodPost, "http://localhost:29900/v1/maintenance/collapse-chains", http.NoBody)
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then every redirect in a chain points at the end of the chain", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/a"), ShouldEqual, "/e")
+				So(storedTarget(values, "/b"), ShouldEqual, "/e")
+				So(storedTarget(values, "/c"), ShouldEqual, "/e")
+				So(storedTarget(values, "/d"), ShouldEqual, "/e")
+			})
+
+			Convey("And loops, prefix redirects and redirects that are not active are left alone", func() {
+				So(storedTarget(values, "/loop1"), ShouldEqual, "/loop2")
+				So(storedTarget(values, "/loop2"), ShouldEqual, "/loop1")
+				So(storedTarget(values, "/later"), ShouldEqual, "/scheduled")
+				So(storedTarget(values, "/scheduled"), ShouldEqual, "/a")
+				So(storedTarget(values, "/economy/*"), ShouldEqual, "/b/*")
+			})
+
+			Convey("And every changed redirect is reported with who changed it", func() {
+				var response models.CollapsedRedirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Count, ShouldEqual, 3)
+				for _, redirect := range response.Items {
+					So(redirect.To, ShouldEqual, "/e")
+					So(redirect.UpdatedBy, ShouldEqual, testUserID)
+				}
+			})
+		})
+	})
+
+	Convey("Given chains that pass through temporary and time limited redirects", t, func() {
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		values := map[string]string{
+			"/t1": "/t2",
+			"/t2": `{"to":"/t3","status_code":302}`,
+			"/t3": "/t4",
+			"/v1": "/v2",
+			"/v2": `{"to":"/v3","valid_until":"` + future + `"}`,
+			"/v3": "/v4",
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		Convey("When the chains are collapsed", func() {
+			responseRecorder := collapseChains(redirectAPI)
+
+			Convey("Then redirects leading to a temporary or time limited redirect are left pointing at it", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/t1"), ShouldEqual, "/t2")
+				So(storedTarget(values, "/v1"), ShouldEqual, "/v2")
+			})
+
+			Convey("And the chains after them are still collapsed", func() {
+				So(storedTarget(values, "/t2"), ShouldEqual, "/t4")
+				So(storedTarget(values, "/v2"), ShouldEqual, "/v4")
+			})
+		})
+	})
+
+	Convey("Given a chain of redirects", t, func() {
+		values := map[string]string{
+			"/a": "/b",
+			"/b": "/c",
+		}
+		mockStore := storetest.NewMapStorer(values)
+		compareAndSwap := mockStore.CompareAndSwapValueFunc
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the start of the chain is pointed somewhere else while the chain is collapsed", func() {
+			mockStore.CompareAndSwapValueFunc = func(ctx context.Context, key, expected string, value interface{}, expiration time.Duration) error {
+				if len(mockStore.CompareAndSwapValueCalls()) == 1 {
+					values[key] = "/elsewhere"
+				}
+				return compareAndSwap(ctx, key, expected, value, expiration)
+			}
+			responseRecorder := collapseChains(redirectAPI)
+
+			Convey("Then the change is kept and the redirect is not reported", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/a"), ShouldEqual, "/elsewhere")
+
+				var response models.CollapsedRedirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Count, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When another field of the start of the chain is changed while the chain is collapsed", func() {
+			mockStore.CompareAndSwapValueFunc = func(ctx context.Context, key, expected string, value interface{}, expiration time.Duration) error {
+				if len(mockStore.CompareAndSwapValueCalls()) == 1 {
+					values[key] = `{"to":"/b","status_code":308}`
+				}
+				return compareAndSwap(ctx, key, expected, value, expiration)
+			}
+			responseRecorder := collapseChains(redirectAPI)
+
+			Convey("Then the redirect is read again and pointed at the end of the chain, keeping the change", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 2)
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/a"]), &stored), ShouldBeNil)
+				So(stored.To, ShouldEqual, "/c")
+				So(stored.StatusCode, ShouldEqual, http.StatusPermanentRedirect)
+			})
+		})
+
+		Convey("When the start of the chain keeps being changed while the chain is collapsed", func() {
+			mockStore.CompareAndSwapValueFunc = func(_ context.Context, _, _ string, _ interface{}, _ time.Duration) error {
+				return store.ErrValueChanged
+			}
+			responseRecorder := collapseChains(redirectAPI)
+
+			Convey("Then it is left alone after retrying", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 3)
+				So(storedTarget(values, "/a"), ShouldEqual, "/b")
+
+				var response models.CollapsedRedirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Count, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func collapseChains(redirectAPI *api.RedirectAPI) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "http://localhost:29900/v1/maintenance/collapse-chains", http.NoBody)
+	request.Header.Set("Authorization", "Bearer "+testUserToken)
+	responseRecorder := httptest.NewRecorder()
+	redirectAPI.Router.ServeHTTP(responseRecorder, request)
+	return responseRecorder
+}
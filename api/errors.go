@@ -1,6 +1,12 @@
 package api
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+)
 
 // A list of error messages for Redirect API
 var (
@@ -8,10 +14,119 @@ var (
 	ErrInternal                = errors.New("internal error")
 	ErrInvalidCount            = errors.New("the count must be an integer giving the requested number of redirects")
 	ErrInvalidOrNegativeCursor = errors.New("the redirects cursor was invalid. It must be a positive integer")
+	ErrInvalidCollapseChains   = errors.New("the collapse_chains value must be true or false")
+	ErrInvalidAtomic           = errors.New("the atomic value must be true or false")
+	ErrInvalidDryRun           = errors.New("the dry_run value must be true or false")
+	ErrUnsupportedMediaType    = errors.New("the content type must be application/json or text/csv")
+	ErrInvalidExportFormat     = errors.New("the format must be ndjson or csv")
+	ErrUnsupportedPatchType    = errors.New("the content type must be application/merge-patch+json or application/json")
+	ErrTooManyItems            = errors.New("the request contains more than the maximum number of redirects")
+	ErrRequestBodyTooLarge     = errors.New("the request body is larger than the maximum size")
+	ErrInvalidCSV              = errors.New("the CSV provided is invalid")
+	ErrInvalidImportColumns    = errors.New("the CSV header must name a 'from' and a 'to' column, and only columns for the fields of a redirect")
+	ErrInvalidImportRow        = errors.New("the row does not have the same number of columns as the header")
+	ErrInvalidImportValue      = errors.New("the value is invalid for the column")
+	ErrDuplicateImportRow      = errors.New("the redirect is also imported by an earlier row")
+	ErrImportNotRolledBack     = errors.New("the redirect was changed by another request after it was imported, so it was not rolled back")
+	ErrInvalidBulkDelete       = errors.New("either 'ids' and 'paths', or 'prefix' must be provided, but not both")
+	ErrInvalidDeletePath       = errors.New("the path must start with '/' or '^/'")
+	ErrInvalidAllHosts         = errors.New("'all_hosts' can only be set with a 'prefix' and no 'host'")
+	ErrDuplicateDeleteItem     = errors.New("the redirect is also listed earlier")
 	ErrInvalidBase64Id         = errors.New("the base64 id provided is invalid")
 	ErrNotFound                = errors.New("not found")
 	ErrInvalidRequestBody      = errors.New("the request body provided is invalid")
+	ErrInvalidMergePatch       = errors.New("the merge patch must be a JSON object")
+	ErrInvalidPrefix           = errors.New("'prefix' must start with '/' or '^/'")
+	ErrInvalidPath             = errors.New("'path' must be a relative path starting with '/'")
+	ErrInvalidRedirectPath     = errors.New("'path' must be the 'from' path or pattern of a redirect, starting with '/' or '^/'")
+	ErrResolveLoop             = errors.New("the path is redirected in a loop")
+	ErrTooManyHops             = errors.New("the path is redirected more times than the maximum number of hops")
+	ErrPreconditionFailed      = errors.New("the redirect does not match the If-Match header")
+	ErrRedirectExists          = errors.New("the redirect already exists")
+	ErrWriteConflict           = errors.New("the redirect was changed by another request at the same time, try again")
 	ErrIDFromMismatch          = errors.New("the 'from' field does not match the base64 id")
+	ErrInvalidHost             = errors.New("'host' must be a lowercase hostname, optionally followed by a port")
 	ErrFromToNotRelative       = errors.New("'from' and 'to' must be relative paths starting with '/'")
+	ErrExternalHostNotAllowed  = errors.New("'to' must be a relative path or an absolute URL on an allowed external host")
 	ErrCircularPaths           = errors.New("'from' and 'to' cannot be the same")
+	ErrRedirectLoop            = errors.New("the redirect would create a loop with existing redirects")
+	ErrRedirectChainTooLong    = errors.New("the redirect would create a chain of redirects longer than the maximum depth")
+	ErrInvalidRedirectType     = errors.New("'type' must be one of exact, prefix or regex")
+	ErrInvalidPrefixRedirect   = errors.New("prefix redirects must have a 'from' path ending in '/*' and no other wildcards")
+	ErrUnexpectedWildcard      = errors.New("only prefix redirects can contain the '*' wildcard")
+	ErrInvalidRegexRedirect    = errors.New("regex redirects must have a 'from' pattern starting with '^/'")
+	ErrInvalidRegexPattern     = errors.New("the 'from' pattern of the regex redirect is invalid")
+	ErrInvalidStatusCode       = errors.New("'status_code' must be one of 301, 302, 307 or 308")
+	ErrInvalidQueryPolicy      = errors.New("'query_policy' must be one of preserve, drop, merge or replace")
+	ErrInvalidTTL              = errors.New("'ttl' must be a positive number of seconds")
+	ErrTTLAndExpiresAt         = errors.New("only one of 'ttl' and 'expires_at' can be provided, unless they agree")
+	ErrExpiresAtInPast         = errors.New("'expires_at' must be in the future")
+	ErrInvalidValidityWindow   = errors.New("'valid_until' must be after 'valid_from'")
 )
+
+// errorCodes are the codes returned in the body of an error response to identify each error
+var errorCodes = map[error]string{
+	ErrNegativeCount:           models.ErrorCodeNegativeCount,
+	ErrInternal:                models.ErrorCodeInternal,
+	ErrInvalidCount:            models.ErrorCodeInvalidCount,
+	ErrInvalidOrNegativeCursor: models.ErrorCodeInvalidCursor,
+	ErrInvalidCollapseChains:   models.ErrorCodeInvalidCollapseChains,
+	ErrInvalidAtomic:           models.ErrorCodeInvalidAtomic,
+	ErrInvalidDryRun:           models.ErrorCodeInvalidDryRun,
+	ErrUnsupportedMediaType:    models.ErrorCodeUnsupportedMediaType,
+	ErrInvalidExportFormat:     models.ErrorCodeInvalidExportFormat,
+	ErrUnsupportedPatchType:    models.ErrorCodeUnsupportedPatchType,
+	ErrTooManyItems:            models.ErrorCodeTooManyItems,
+	ErrRequestBodyTooLarge:     models.ErrorCodeRequestBodyTooLarge,
+	ErrInvalidCSV:              models.ErrorCodeInvalidCSV,
+	ErrInvalidImportColumns:    models.ErrorCodeInvalidImportColumns,
+	ErrInvalidImportRow:        models.ErrorCodeInvalidImportRow,
+	ErrInvalidImportValue:      models.ErrorCodeInvalidImportValue,
+	ErrDuplicateImportRow:      models.ErrorCodeDuplicateImportRow,
+	ErrInvalidBulkDelete:       models.ErrorCodeInvalidBulkDelete,
+	ErrInvalidDeletePath:       models.ErrorCodeInvalidDeletePath,
+	ErrInvalidAllHosts:         models.ErrorCodeInvalidAllHosts,
+	ErrDuplicateDeleteItem:     models.ErrorCodeDuplicateDeleteItem,
+	ErrInvalidBase64Id:         models.ErrorCodeInvalidID,
+	ErrNotFound:                models.ErrorCodeNotFound,
+	ErrInvalidRequestBody:      models.ErrorCodeInvalidRequestBody,
+	ErrInvalidMergePatch:       models.ErrorCodeInvalidMergePatch,
+	ErrInvalidPrefix:           models.ErrorCodeInvalidPrefix,
+	ErrInvalidPath:             models.ErrorCodeInvalidPath,
+	ErrInvalidRedirectPath:     models.ErrorCodeInvalidRedirectPath,
+	ErrResolveLoop:             models.ErrorCodeResolveLoop,
+	ErrTooManyHops:             models.ErrorCodeTooManyHops,
+	ErrPreconditionFailed:      models.ErrorCodePreconditionFailed,
+	ErrRedirectExists:          models.ErrorCodeRedirectExists,
+	ErrWriteConflict:           models.ErrorCodeWriteConflict,
+	ErrIDFromMismatch:          models.ErrorCodeIDFromMismatch,
+	ErrInvalidHost:             models.ErrorCodeInvalidHost,
+	ErrFromToNotRelative:       models.ErrorCodeFromToNotRelative,
+	ErrExternalHostNotAllowed:  models.ErrorCodeExternalHostNotAllowed,
+	ErrCircularPaths:           models.ErrorCodeCircularPaths,
+	ErrRedirectLoop:            models.ErrorCodeRedirectLoop,
+	ErrRedirectChainTooLong:    models.ErrorCodeRedirectChainTooLong,
+	ErrInvalidRedirectType:     models.ErrorCodeInvalidRedirectType,
+	ErrInvalidPrefixRedirect:   models.ErrorCodeInvalidPrefixRedirect,
+	ErrUnexpectedWildcard:      models.ErrorCodeUnexpectedWildcard,
+	ErrInvalidRegexRedirect:    models.ErrorCodeInvalidRegexRedirect,
+	ErrInvalidRegexPattern:     models.ErrorCodeInvalidRegexPattern,
+	ErrInvalidStatusCode:       models.ErrorCodeInvalidStatusCode,
+	ErrInvalidQueryPolicy:      models.ErrorCodeInvalidQueryPolicy,
+	ErrInvalidTTL:              models.ErrorCodeInvalidTTL,
+	ErrTTLAndExpiresAt:         models.ErrorCodeTTLAndExpiresAt,
+	ErrExpiresAtInPast:         models.ErrorCodeExpiresAtInPast,
+	ErrInvalidValidityWindow:   models.ErrorCodeInvalidValidityWindow,
+}
+
+// errorCode returns the code identifying the given error, or a code derived from the HTTP status if the error
+// has no code of its own
+func errorCode(err error, status int) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if code, ok := errorCodes[err]; ok {
+			return code
+		}
+	}
+
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
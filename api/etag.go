@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+)
+
+// The headers used for conditional requests
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// preconditions are the conditions a request places on the current state of a redirect before changing it
+type preconditions struct {
+	// ifMatch is the value of the If-Match header, or empty if the request is unconditional
+	ifMatch string
+	// ifNoneMatch is the value of the If-None-Match header, where "*" only allows a redirect to be created
+	ifNoneMatch string
+	// version is the stored version of the redirect that the change was made to, or empty if the change does not
+	// depend on the redirect it replaces
+	version string
+}
+
+// requestPreconditions returns the preconditions given in the headers of a request
+func requestPreconditions(r *http.Request) preconditions {
+	return preconditions{
+		ifMatch:     r.Header.Get(HeaderIfMatch),
+		ifNoneMatch: r.Header.Get(HeaderIfNoneMatch),
+	}
+}
+
+// conditional returns true if the request only changes a redirect in a particular state
+func (p preconditions) conditional() bool {
+	return p.ifMatch != "" || p.ifNoneMatch != ""
+}
+
+// check returns ErrPreconditionFailed if the given current redirect, which is nil if the redirect does not
+// exist, does not match the If-Match header, or ErrRedirectExists if it matches the If-None-Match header
+func (p preconditions) check(current *models.Redirect) error {
+	if p.ifMatch != "" && (current == nil || !matchesETag(p.ifMatch, current.ETag(), false)) {
+		return ErrPreconditionFailed
+	}
+
+	if p.ifNoneMatch != "" && current != nil && matchesETag(p.ifNoneMatch, current.ETag(), true) {
+		return ErrRedirectExists
+	}
+
+	return nil
+}
+
+// matchesETag returns true if the given list of entity tags from an If-Match or If-None-Match header includes
+// the given tag or is "*". Weak comparison, as used for If-None-Match, ignores the weak indicator of a tag, while
+// strong comparison, as used for If-Match, never matches a weak tag.
+func matchesETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = strings.TrimPrefix(tag, "W/")
+		}
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,197 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	Convey("Given a stored redirect", t, func() {
+		values := map[string]string{
+			"/economy": `{"to":"/business","updated_at":"2025-01-02T09:30:00Z","updated_by":"editor@ons.gov.uk"}`,
+		}
+		mockStore := storetest.NewMapStorer(values)
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+		id := encodeBase64("/economy")
+
+		sendRequest := func(method, id string, headers map[string]string, body string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(method, "http://localhost:29900/v1/redirects/"+id, strings.NewReader(body))
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			for name, value := range headers {
+				request.Header.Set(name, value)
+			}
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		etag := sendRequest(http.MethodGet, id, nil, "").Header().Get(api.HeaderETag)
+
+		Convey("When the redirect is got", func() {
+			responseRecorder := sendRequest(http.MethodGet, id, nil, "")
+
+			Convey("Then a strong ETag is returned that does not change between requests", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(etag, ShouldStartWith, `"`)
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldEqual, etag)
+			})
+		})
+
+		Convey("When the redirect is got with an If-None-Match header matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodGet, id, map[string]string{api.HeaderIfNoneMatch: `"other", W/` + etag}, "")
+
+			Convey("Then the response status code should be 304 with no body", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotModified)
+				So(responseRecorder.Body.String(), ShouldBeEmpty)
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldEqual, etag)
+			})
+		})
+
+		Convey("When the redirect is got with an If-None-Match header not matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodGet, id, map[string]string{api.HeaderIfNoneMatch: `"other"`}, "")
+
+			Convey("Then the redirect is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, "/business")
+			})
+		})
+
+		Convey("When the redirect is updated with an If-Match header matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodPut, id, map[string]string{api.HeaderIfMatch: etag}, `{"from": "/economy", "to": "/business/new"}`)
+
+			Convey("Then the redirect is updated and its new ETag is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/business/new")
+
+				newETag := responseRecorder.Header().Get(api.HeaderETag)
+				So(newETag, ShouldNotEqual, etag)
+				So(sendRequest(http.MethodGet, id, nil, "").Header().Get(api.HeaderETag), ShouldEqual, newETag)
+			})
+		})
+
+		Convey("When the redirect is updated with an If-Match header not matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodPut, id, map[string]string{api.HeaderIfMatch: `"stale"`}, `{"from": "/economy", "to": "/business/new"}`)
+
+			Convey("Then the response status code should be 412 and the redirect is unchanged", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrPreconditionFailed.Error())
+				So(storedTarget(values, "/economy"), ShouldEqual, "/business")
+			})
+		})
+
+		Convey("When the redirect is updated with a weak ETag in the If-Match header", func() {
+			responseRecorder := sendRequest(http.MethodPut, id, map[string]string{api.HeaderIfMatch: "W/" + etag}, `{"from": "/economy", "to": "/business/new"}`)
+
+			Convey("Then the response status code should be 412, as If-Match uses strong comparison", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+			})
+		})
+
+		Convey("When a redirect that does not exist is created with an If-Match header", func() {
+			responseRecorder := sendRequest(http.MethodPut, encodeBase64("/census"), map[string]string{api.HeaderIfMatch: "*"}, `{"from": "/census", "to": "/people"}`)
+
+			Convey("Then the response status code should be 412 and nothing is created", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(values, ShouldNotContainKey, "/census")
+			})
+		})
+
+		Convey("When the redirect is created with an If-None-Match header of \"*\" but it already exists", func() {
+			responseRecorder := sendRequest(http.MethodPut, id, map[string]string{api.HeaderIfNoneMatch: "*"}, `{"from": "/economy", "to": "/business/new"}`)
+
+			Convey("Then the response status code should be 412 with the existing redirect and its ETag, which is unchanged", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldEqual, etag)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/business")
+
+				var errorResponse models.ErrorResponse
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &errorResponse), ShouldBeNil)
+				So(errorResponse.Code, ShouldEqual, models.ErrorCodeRedirectExists)
+				So(errorResponse.Existing, ShouldNotBeNil)
+				So(errorResponse.Existing.ID, ShouldEqual, id)
+				So(errorResponse.Existing.To, ShouldEqual, "/business")
+				So(errorResponse.Existing.UpdatedBy, ShouldEqual, "editor@ons.gov.uk")
+			})
+		})
+
+		Convey("When a redirect that does not exist is created with an If-None-Match header of \"*\"", func() {
+			responseRecorder := sendRequest(http.MethodPut, encodeBase64("/census"), map[string]string{api.HeaderIfNoneMatch: "*"}, `{"from": "/census", "to": "/people"}`)
+
+			Convey("Then the redirect is created", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(storedTarget(values, "/census"), ShouldEqual, "/people")
+			})
+		})
+
+		Convey("When the redirect is updated with an If-None-Match header not matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodPut, id, map[string]string{api.HeaderIfNoneMatch: `"other"`}, `{"from": "/economy", "to": "/business/new"}`)
+
+			Convey("Then the redirect is updated", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/business/new")
+			})
+		})
+
+		Convey("When the redirect is patched with an If-Match header matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodPatch, id, map[string]string{api.HeaderIfMatch: etag}, `{"status_code": 308}`)
+
+			Convey("Then the redirect is updated and its new ETag is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldEqual, sendRequest(http.MethodGet, id, nil, "").Header().Get(api.HeaderETag))
+			})
+		})
+
+		Convey("When the redirect is patched with an If-Match header not matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodPatch, id, map[string]string{api.HeaderIfMatch: `"stale"`}, `{"status_code": 308}`)
+
+			Convey("Then the response status code should be 412", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect is deleted with an If-Match header matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodDelete, id, map[string]string{api.HeaderIfMatch: etag}, "")
+
+			Convey("Then the redirect is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNoContent)
+				So(values, ShouldNotContainKey, "/economy")
+			})
+		})
+
+		Convey("When the redirect is deleted with an If-Match header not matching its ETag", func() {
+			responseRecorder := sendRequest(http.MethodDelete, id, map[string]string{api.HeaderIfMatch: `"stale"`}, "")
+
+			Convey("Then the response status code should be 412 and the redirect is not deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(values, ShouldContainKey, "/economy")
+			})
+		})
+
+		Convey("When a redirect that does not exist is deleted with an If-Match header", func() {
+			responseRecorder := sendRequest(http.MethodDelete, encodeBase64("/census"), map[string]string{api.HeaderIfMatch: "*"}, "")
+
+			Convey("Then the response status code should be 412", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+			})
+		})
+
+		Convey("When the redirect is deleted with an If-None-Match header of \"*\"", func() {
+			responseRecorder := sendRequest(http.MethodDelete, id, map[string]string{api.HeaderIfNoneMatch: "*"}, "")
+
+			Convey("Then the response status code should be 412 and the redirect is not deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(values, ShouldContainKey, "/economy")
+			})
+		})
+	})
+}
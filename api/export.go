@@ -0,0 +1,249 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	"github.com/ONSdigital/dp-net/v2/links"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// The formats that redirects can be exported in
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatCSV    = "csv"
+
+	mediaTypeNDJSON = "application/x-ndjson"
+)
+
+// exportColumns are the columns of a CSV export, in order. A TTL is not exported, as the expiry time of a
+// redirect is exported instead.
+var exportColumns = []string{
+	columnID,
+	columnHost,
+	columnFrom,
+	columnTo,
+	columnType,
+	columnStatusCode,
+	columnQueryPolicy,
+	columnExpiresAt,
+	columnValidFrom,
+	columnValidUntil,
+	columnStatus,
+	columnCreatedAt,
+	columnCreatedBy,
+	columnUpdatedAt,
+	columnUpdatedBy,
+}
+
+// redirectEncoder writes redirects to an export one at a time
+type redirectEncoder interface {
+	Encode(redirect *models.Redirect) error
+	Flush() error
+}
+
+// exportRedirects handles exporting every redirect, or those whose 'from' path or pattern starts with a prefix,
+// as NDJSON or CSV. The redirects are written as the store is scanned, so they are never all held in memory, and
+// the deadline for writing the response is extended as each is written, so that a large export is not cut off.
+func (api *RedirectAPI) exportRedirects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get(QueryParameterFormat)
+	if format == "" {
+		format = exportFormatNDJSON
+	}
+	filter := store.RedirectFilter{
+		Host:   r.URL.Query().Get(QueryParameterHost),
+		Prefix: r.URL.Query().Get(QueryParameterPrefix),
+	}
+	logData := log.Data{QueryParameterFormat: format, QueryParameterHost: filter.Host, QueryParameterPrefix: filter.Prefix}
+
+	if format != exportFormatNDJSON && format != exportFormatCSV {
+		log.Info(ctx, "invalid query parameter - format should be ndjson or csv", logData)
+		api.handleError(ctx, w, ErrInvalidExportFormat, http.StatusBadRequest)
+		return
+	}
+
+	if filter.Host != "" && !models.IsValidHost(filter.Host) {
+		log.Info(ctx, "invalid query parameter - host should be a lowercase hostname", logData)
+		api.handleError(ctx, w, ErrInvalidHost, http.StatusBadRequest)
+		return
+	}
+
+	if !isValidPrefix(filter.Prefix) {
+		log.Info(ctx, "invalid query parameter - prefix should start with a '/' or the regex anchor", logData)
+		api.handleError(ctx, w, ErrInvalidPrefix, http.StatusBadRequest)
+		return
+	}
+
+	linkBuilder := links.FromHeadersOrDefault(&r.Header, api.apiURL)
+	now := time.Now().UTC()
+
+	// the export is only started once the first redirect has been read, so that a store that fails
+	// straight away can still be reported with an error response
+	var encoder redirectEncoder
+	count := 0
+	err := api.RedirectStore.WalkRedirects(ctx, filter, func(redirect *models.Redirect) error {
+		if err := setRedirectLinks(linkBuilder, redirect); err != nil {
+			return fmt.Errorf("failed to build redirect link: %w", err)
+		}
+		redirect.Status = redirect.ActivationStatus(now)
+
+		extendDeadlines(ctx, w)
+		if encoder == nil {
+			encoder = startExport(w, format)
+		}
+		count++
+		return encoder.Encode(redirect)
+	})
+	extendDeadlines(ctx, w)
+	logData["num_redirects"] = count
+	if err != nil {
+		if encoder == nil {
+			log.Error(ctx, "redis failed on exporting redirects", err, logData)
+			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+			return
+		}
+
+		// the response has already started, so it is aborted so that the client does not mistake the
+		// redirects written so far for a complete export
+		log.Error(ctx, "failed to export every redirect", err, logData)
+		panic(http.ErrAbortHandler)
+	}
+
+	if encoder == nil {
+		encoder = startExport(w, format)
+	}
+	if err := encoder.Flush(); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+		return
+	}
+
+	log.Info(ctx, "redirects exported", logData)
+}
+
+// startExport writes the headers of an export in the given format and returns the encoder to write each
+// redirect with
+func startExport(w http.ResponseWriter, format string) redirectEncoder {
+	if format == exportFormatCSV {
+		w.Header().Set("Content-Type", mediaTypeCSV)
+		w.Header().Set("Content-Disposition", `attachment; filename="redirects.csv"`)
+		return &csvRedirectEncoder{writer: csv.NewWriter(w)}
+	}
+
+	w.Header().Set("Content-Type", mediaTypeNDJSON)
+	w.Header().Set("Content-Disposition", `attachment; filename="redirects.ndjson"`)
+	return &ndjsonRedirectEncoder{encoder: json.NewEncoder(w)}
+}
+
+// ndjsonRedirectEncoder writes each redirect as a JSON object on its own line
+type ndjsonRedirectEncoder struct {
+	encoder *json.Encoder
+}
+
+// Encode writes the redirect as a line of the export
+func (e *ndjsonRedirectEncoder) Encode(redirect *models.Redirect) error {
+	return e.encoder.Encode(redirect)
+}
+
+// Flush does nothing, as each line is written as soon as it is encoded
+func (e *ndjsonRedirectEncoder) Flush() error {
+	return nil
+}
+
+// csvRedirectEncoder writes each redirect as a row of a CSV file, under a header naming the exportColumns
+type csvRedirectEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// Encode writes the redirect as a row of the export, writing the header first if it has not been written
+func (e *csvRedirectEncoder) Encode(redirect *models.Redirect) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	record := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		record[i] = csvValue(redirect, column)
+	}
+
+	return e.writer.Write(record)
+}
+
+// Flush writes the header if no redirects were exported, then writes any buffered rows
+func (e *csvRedirectEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// writeHeader writes the header of the export, unless it has already been written
+func (e *csvRedirectEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+
+	return e.writer.Write(exportColumns)
+}
+
+// csvValue returns the value of the given column of a CSV export for the redirect. Times are written in
+// RFC 3339 format, as they are in JSON, and unset values are left empty.
+func csvValue(redirect *models.Redirect, column string) string {
+	switch column {
+	case columnID:
+		return redirect.ID
+	case columnHost:
+		return redirect.Host
+	case columnFrom:
+		return redirect.From
+	case columnTo:
+		return redirect.To
+	case columnType:
+		return redirect.Type
+	case columnStatusCode:
+		if redirect.StatusCode == 0 {
+			return ""
+		}
+		return strconv.Itoa(redirect.StatusCode)
+	case columnQueryPolicy:
+		return redirect.QueryPolicy
+	case columnExpiresAt:
+		return formatExportTime(redirect.ExpiresAt)
+	case columnValidFrom:
+		return formatExportTime(redirect.ValidFrom)
+	case columnValidUntil:
+		return formatExportTime(redirect.ValidUntil)
+	case columnStatus:
+		return redirect.Status
+	case columnCreatedAt:
+		return formatExportTime(redirect.CreatedAt)
+	case columnCreatedBy:
+		return redirect.CreatedBy
+	case columnUpdatedAt:
+		return formatExportTime(redirect.UpdatedAt)
+	case columnUpdatedBy:
+		return redirect.UpdatedBy
+	default:
+		return ""
+	}
+}
+
+// formatExportTime formats a time in RFC 3339 format, returning an empty string if it is not set
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339Nano)
+}
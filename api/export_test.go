@@ -0,0 +1,231 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	"github.com/ONSdigital/log.go/v2/log"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const exportURL = "http://localhost:29900/v1/bulk/export"
+
+func exportRedirects(redirectAPI *api.RedirectAPI, query string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, exportURL+query, http.NoBody)
+	responseRecorder := httptest.NewRecorder()
+	redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+	return responseRecorder
+}
+
+func TestExportRedirects(t *testing.T) {
+	Convey("Given a store with redirects", t, func() {
+		values := map[string]string{
+			"/economy":               `{"to":"/business","status_code":302,"created_at":"2025-01-02T09:30:00Z","created_by":"creator@ons.gov.uk"}`,
+			"/economy/inflation":     "/business/inflation",
+			"//cy.ons.gov.uk/census": `{"to":"/people","valid_until":"2099-01-01T00:00:00Z"}`,
+			"/census":                "/people",
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		Convey("When the redirects are exported", func() {
+			responseRecorder := exportRedirects(redirectAPI, "")
+
+			Convey("Then every redirect is written as a line of NDJSON", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(responseRecorder.Header().Get("Content-Type"), ShouldEqual, "application/x-ndjson")
+				So(responseRecorder.Header().Get("Content-Disposition"), ShouldContainSubstring, "redirects.ndjson")
+
+				var exported []models.Redirect
+				scanner := bufio.NewScanner(responseRecorder.Body)
+				for scanner.Scan() {
+					var redirect models.Redirect
+					So(json.Unmarshal(scanner.Bytes(), &redirect), ShouldBeNil)
+					exported = append(exported, redirect)
+				}
+				So(exported, ShouldHaveLength, 4)
+				So(exported[0].Host, ShouldEqual, "cy.ons.gov.uk")
+				So(exported[0].From, ShouldEqual, "/census")
+			})
+		})
+
+		Convey("When the redirects starting with a prefix are exported", func() {
+			responseRecorder := exportRedirects(redirectAPI, "?prefix=/economy")
+
+			Convey("Then only those redirects are written", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var exported []models.Redirect
+				scanner := bufio.NewScanner(responseRecorder.Body)
+				for scanner.Scan() {
+					var redirect models.Redirect
+					So(json.Unmarshal(scanner.Bytes(), &redirect), ShouldBeNil)
+					exported = append(exported, redirect)
+				}
+				So(exported, ShouldHaveLength, 2)
+				So(exported[0].From, ShouldEqual, "/economy")
+				So(exported[0].ID, ShouldEqual, encodeBase64("/economy"))
+				So(exported[0].Status, ShouldEqual, models.RedirectStatusActive)
+				So(exported[0].CreatedBy, ShouldEqual, "creator@ons.gov.uk")
+				So(exported[1].From, ShouldEqual, "/economy/inflation")
+			})
+		})
+
+		Convey("When the redirects are exported as CSV", func() {
+			responseRecorder := exportRedirects(redirectAPI, "?format=csv&host=cy.ons.gov.uk")
+
+			Convey("Then each redirect is written as a row under a header", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(responseRecorder.Header().Get("Content-Type"), ShouldEqual, "text/csv")
+
+				records, err := csv.NewReader(responseRecorder.Body).ReadAll()
+				So(err, ShouldBeNil)
+				So(records, ShouldHaveLength, 2)
+				So(records[0][:4], ShouldResemble, []string{"id", "host", "from", "to"})
+				So(records[1][:4], ShouldResemble, []string{encodeBase64("//cy.ons.gov.uk/census"), "cy.ons.gov.uk", "/census", "/people"})
+				So(records[1], ShouldContain, "2099-01-01T00:00:00Z")
+			})
+		})
+
+		Convey("When a CSV export is imported into an empty store", func() {
+			exported := exportRedirects(redirectAPI, "?format=csv").Body.String()
+
+			importedValues := map[string]string{}
+			importAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(importedValues)})
+			request := httptest.NewRequest(http.MethodPost, importURL, strings.NewReader(exported))
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			request.Header.Set("Content-Type", "text/csv")
+			responseRecorder := httptest.NewRecorder()
+			importAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then every redirect is recreated", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var report models.ImportReport
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &report), ShouldBeNil)
+				So(report.Created, ShouldEqual, 4)
+				So(storedTarget(importedValues, "/economy"), ShouldEqual, "/business")
+				So(storedTarget(importedValues, "//cy.ons.gov.uk/census"), ShouldEqual, "/people")
+			})
+		})
+
+		Convey("When no redirects are selected for a CSV export", func() {
+			responseRecorder := exportRedirects(redirectAPI, "?format=csv&prefix=/nothing")
+
+			Convey("Then only the header is written", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				records, err := csv.NewReader(responseRecorder.Body).ReadAll()
+				So(err, ShouldBeNil)
+				So(records, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the format is not supported", func() {
+			responseRecorder := exportRedirects(redirectAPI, "?format=xml")
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidExportFormat.Error())
+			})
+		})
+
+		Convey("When the prefix does not start with '/'", func() {
+			responseRecorder := exportRedirects(redirectAPI, "?prefix=economy")
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidPrefix.Error())
+			})
+		})
+	})
+
+	Convey("Given a store that fails", t, func() {
+		errStore := errors.New("redis error")
+		nextCursor := uint64(0)
+		mockStore := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, cursor uint64) (map[string]string, uint64, error) {
+				if cursor == 0 && nextCursor != 0 {
+					return map[string]string{"/economy": "/business"}, nextCursor, nil
+				}
+				return nil, 0, errStore
+			},
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the store fails before any redirects are exported", func() {
+			responseRecorder := exportRedirects(redirectAPI, "")
+
+			Convey("Then the response status code should be 500", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+
+		Convey("When the store fails after some redirects are exported", func() {
+			nextCursor = 7
+
+			Convey("Then the response is aborted", func() {
+				So(func() { exportRedirects(redirectAPI, "") }, ShouldPanicWith, http.ErrAbortHandler)
+			})
+		})
+	})
+}
+
+func TestExportRedirectsOutlastsWriteTimeout(t *testing.T) {
+	Convey("Given a store that takes longer to read than the write timeout of the server", t, func() {
+		writeTimeout := 200 * time.Millisecond
+		pages := []map[string]string{
+			{"/economy": "/business"},
+			{"/census": "/people"},
+			{"/inflation": "/business/inflation"},
+		}
+		mockStore := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, cursor uint64) (map[string]string, uint64, error) {
+				time.Sleep(writeTimeout * 3 / 4)
+				next := cursor + 1
+				if next == uint64(len(pages)) {
+					next = 0
+				}
+				return pages[cursor], next, nil
+			},
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		// the log middleware wraps the response writer as it is in the service, so its deadlines cannot be changed
+		server := httptest.NewUnstartedServer(log.Middleware(redirectAPI.Router))
+		server.Config.WriteTimeout = writeTimeout
+		server.Config.ConnContext = api.ConnContext
+		server.Start()
+		defer server.Close()
+
+		Convey("When the redirects are exported", func() {
+			response, err := http.Get(server.URL + "/v1/bulk/export")
+			So(err, ShouldBeNil)
+			defer response.Body.Close()
+
+			Convey("Then every redirect is written", func() {
+				So(response.StatusCode, ShouldEqual, http.StatusOK)
+
+				lines := 0
+				scanner := bufio.NewScanner(response.Body)
+				for scanner.Scan() {
+					lines++
+				}
+				So(scanner.Err(), ShouldBeNil)
+				So(lines, ShouldEqual, len(pages))
+			})
+		})
+	})
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+)
+
+// serviceIdentityTTL is how long the identity zebedee gives for a service token is remembered, so that a service
+// making many writes does not have zebedee check its token for each of them
+const serviceIdentityTTL = time.Minute
+
+// serviceIdentity is the identity zebedee gave for a service token, and when it is no longer remembered
+type serviceIdentity struct {
+	identifier string
+	expiresAt  time.Time
+}
+
+// serviceIdentities remembers the identities zebedee gave for service tokens for serviceIdentityTTL. The tokens
+// are hashed so that they are not held in memory.
+type serviceIdentities struct {
+	mu         sync.Mutex
+	identities map[[sha256.Size]byte]serviceIdentity
+}
+
+// get returns the identity remembered for the given service token, if it has not expired
+func (s *serviceIdentities) get(token string, now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	identity, ok := s.identities[sha256.Sum256([]byte(token))]
+	if !ok || !now.Before(identity.expiresAt) {
+		return "", false
+	}
+	return identity.identifier, true
+}
+
+// add remembers the identity of the given service token, and forgets any identities that have expired
+func (s *serviceIdentities) add(token, identifier string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.identities == nil {
+		s.identities = map[[sha256.Size]byte]serviceIdentity{}
+	}
+	for key, identity := range s.identities {
+		if !now.Before(identity.expiresAt) {
+			delete(s.identities, key)
+		}
+	}
+	s.identities[sha256.Sum256([]byte(token))] = serviceIdentity{
+		identifier: identifier,
+		expiresAt:  now.Add(serviceIdentityTTL),
+	}
+}
+
+// getCallerIdentity returns the user or service identifier for the token on the request, which has
+// already been verified by the authorisation middleware. An empty identifier is returned when the
+// request has no token or the caller cannot be identified because authorisation is disabled. The identities of
+// service tokens are remembered for a short time, so that zebedee is not asked on every write.
+func (api *RedirectAPI) getCallerIdentity(r *http.Request) (string, error) {
+	authToken := strings.TrimPrefix(r.Header.Get(dprequest.AuthHeaderKey), dprequest.BearerPrefix)
+	if authToken == "" {
+		return "", nil
+	}
+
+	// JWTs identify a user, anything else is a service token that is checked with zebedee
+	if strings.Contains(authToken, ".") {
+		entityData, err := api.authMiddleware.Parse(authToken)
+		if err != nil {
+			return "", err
+		}
+		if entityData == nil {
+			return "", nil
+		}
+		return entityData.UserID, nil
+	}
+
+	if api.zebedeeClient == nil {
+		return "", nil
+	}
+
+	now := time.Now()
+	if identifier, ok := api.serviceIdentities.get(authToken, now); ok {
+		return identifier, nil
+	}
+
+	identityResponse, err := api.zebedeeClient.CheckTokenIdentity(r.Context(), authToken)
+	if err != nil {
+		return "", err
+	}
+
+	api.serviceIdentities.add(authToken, identityResponse.Identifier, now)
+	return identityResponse.Identifier, nil
+}
@@ -0,0 +1,79 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/config"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestServiceIdentity(t *testing.T) {
+	Convey("Given an API that identifies services with zebedee", t, func() {
+		var identityChecks atomic.Int32
+		zebedee := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identityChecks.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"identifier": "service-" + r.Header.Get("Authorization")[len("Bearer "):]})
+		}))
+		defer zebedee.Close()
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		authCfg := *cfg.AuthorisationConfig
+		authCfg.Enabled = true
+		authCfg.ZebedeeURL = zebedee.URL
+		serviceCfg := *cfg
+		serviceCfg.AuthorisationConfig = &authCfg
+
+		values := map[string]string{}
+		redirectAPI := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: storetest.NewMapStorer(values)}, newAuthMiddlwareMock(), &serviceCfg)
+
+		put := func(from, token string) *httptest.ResponseRecorder {
+			body, _ := json.Marshal(models.Redirect{From: from, To: testToURL})
+			request := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+encodeBase64(from), bytes.NewBuffer(body))
+			request.Header.Set("Authorization", "Bearer "+token)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		Convey("When a service makes more than one write with the same token", func() {
+			So(put("/economy", "first-token").Code, ShouldEqual, http.StatusCreated)
+			So(put("/census", "first-token").Code, ShouldEqual, http.StatusCreated)
+
+			Convey("Then its token is only checked with zebedee once", func() {
+				So(identityChecks.Load(), ShouldEqual, 1)
+			})
+
+			Convey("And each redirect records the identity of the service", func() {
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/census"]), &stored), ShouldBeNil)
+				So(stored.CreatedBy, ShouldEqual, "service-first-token")
+			})
+		})
+
+		Convey("When writes are made with different service tokens", func() {
+			So(put("/economy", "first-token").Code, ShouldEqual, http.StatusCreated)
+			So(put("/census", "second-token").Code, ShouldEqual, http.StatusCreated)
+
+			Convey("Then each token is checked with zebedee", func() {
+				So(identityChecks.Load(), ShouldEqual, 2)
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/census"]), &stored), ShouldBeNil)
+				So(stored.CreatedBy, ShouldEqual, "service-second-token")
+			})
+		})
+	})
+}
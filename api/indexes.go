@@ -0,0 +1,37 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// rebuildIndexes adds every stored redirect to the indexes used to look redirects up by their target, so that
+// the redirects written before the indexes existed can be found too
+func (api *RedirectAPI) rebuildIndexes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	count, err := api.RedirectStore.RebuildIndexes(ctx)
+	extendDeadlines(ctx, w)
+	if err != nil {
+		log.Error(ctx, "redis failed on rebuilding indexes", err, log.Data{"num_redirects": count})
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	rebuiltResponse, err := json.Marshal(models.RebuiltIndexes{Count: count})
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info(ctx, "redirect indexes rebuilt", log.Data{"num_redirects": count})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(rebuiltResponse); err != nil {
+		log.Error(ctx, "failed to write response", err)
+	}
+}
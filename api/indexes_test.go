@@ -0,0 +1,62 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRebuildIndexes(t *testing.T) {
+	Convey("Given a store with redirects that are not in the reverse index", t, func() {
+		values := map[string]string{
+			"/economy":               "/business",
+			"//cy.ons.gov.uk/census": "/business?lang=cy",
+			"/census":                "/people",
+		}
+		mockStore := storetest.NewMapStorer(values)
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		getRedirectsTo := func(to string) models.Redirects {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?to="+to, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+			var response models.Redirects
+			So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+			return response
+		}
+
+		Convey("Then the redirects cannot be found by their target", func() {
+			So(getRedirectsTo("/business").RedirectList, ShouldBeEmpty)
+		})
+
+		Convey("When the indexes are rebuilt", func() {
+			request := httptest.NewRequest(http.MethodPost, "http://localhost:29900/v1/maintenance/rebuild-indexes", http.NoBody)
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the number of redirects indexed is reported", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.RebuiltIndexes
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Count, ShouldEqual, 3)
+			})
+
+			Convey("And the redirects can be found by their target", func() {
+				response := getRedirectsTo("/business")
+				So(response.RedirectList, ShouldHaveLength, 2)
+				So(response.RedirectList[0].Key(), ShouldEqual, "//cy.ons.gov.uk/census")
+				So(response.RedirectList[1].Key(), ShouldEqual, "/economy")
+			})
+		})
+	})
+}
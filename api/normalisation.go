@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	"github.com/ONSdigital/dp-net/v2/links"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// canonicalPath returns the normalised form of the given path when path normalisation is enabled. Regex
+// patterns and absolute URLs are returned unchanged.
+func (api *RedirectAPI) canonicalPath(path string) string {
+	if !api.normalisePaths || !strings.HasPrefix(path, "/") {
+		return path
+	}
+
+	return models.NormalisePath(path, api.normalisePathCase)
+}
+
+// canonicalKey returns the key with its 'from' path normalised when path normalisation is enabled
+func (api *RedirectAPI) canonicalKey(key string) string {
+	host, from := models.ParseRedirectKey(key)
+
+	return models.RedirectKey(host, api.canonicalPath(from))
+}
+
+// getCollisions reports the redirects whose keys would be the same once their paths are normalised, and those
+// stored under a key that is not normalised. Only one redirect in each group can be reached once normalisation
+// is enabled, and a redirect stored under a key that is not normalised cannot be reached at all, so they need to
+// be removed, merged or moved before switching it on. The report uses the configured case folding whether or not
+// normalisation is enabled.
+func (api *RedirectAPI) getCollisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	groups := map[string][]models.Redirect{}
+	err := api.RedirectStore.WalkRedirects(ctx, store.RedirectFilter{}, func(redirect *models.Redirect) error {
+		if redirect.Type == models.RedirectTypeRegex {
+			return nil
+		}
+		key := models.RedirectKey(redirect.Host, models.NormalisePath(redirect.From, api.normalisePathCase))
+		groups[key] = append(groups[key], *redirect)
+		return nil
+	})
+	if err != nil {
+		log.Error(ctx, "redis failed on walking redirects", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	linkBuilder := links.FromHeadersOrDefault(&r.Header, api.apiURL)
+
+	collisions := models.RedirectCollisions{Items: []models.RedirectCollision{}}
+	for key, redirects := range groups {
+		if len(redirects) < 2 && redirects[0].Key() == key {
+			continue
+		}
+
+		sort.Slice(redirects, func(i, j int) bool {
+			return redirects[i].Key() < redirects[j].Key()
+		})
+
+		for i := range redirects {
+			if err := setRedirectLinks(linkBuilder, &redirects[i]); err != nil {
+				log.Error(ctx, "redirect builder failed to build link", err)
+				api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+				return
+			}
+		}
+
+		collisions.Items = append(collisions.Items, models.RedirectCollision{
+			Key:       key,
+			Redirects: redirects,
+		})
+	}
+
+	sort.Slice(collisions.Items, func(i, j int) bool {
+		return collisions.Items[i].Key < collisions.Items[j].Key
+	})
+	collisions.Count = len(collisions.Items)
+
+	collisionsResponse, err := json.Marshal(collisions)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(collisionsResponse); err != nil {
+		log.Error(ctx, "failed to write response", err)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,194 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/config"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func getNormalisingRedirectAPIWithMocks(datastore store.Datastore) *api.RedirectAPI {
+	cfg, err := config.Get()
+	So(err, ShouldBeNil)
+
+	normalisingCfg := *cfg
+	normalisingCfg.NormalisePaths = true
+	normalisingCfg.NormalisePathCase = true
+
+	return api.Setup(context.Background(), mux.NewRouter(), &datastore, newAuthMiddlwareMock(), &normalisingCfg)
+}
+
+func TestPathNormalisation(t *testing.T) {
+	Convey("Given an API with path normalisation enabled", t, func() {
+		mockStore := &storetest.StorerMock{
+			GetValueFunc: func(_ context.Context, key string) (string, error) {
+				if key == redirectFrom {
+					return redirectTo, nil
+				}
+				return "", disRedis.ErrKeyNotFound
+			},
+			SetValueFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
+				return nil
+			},
+			SetValueIfAbsentFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+				return true, nil
+			},
+			DeleteValueReturningPreviousFunc: func(_ context.Context, _ string) (string, error) {
+				return redirectTo, nil
+			},
+			AddToSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			RemoveFromSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			GetSortedSetRangeByLexFunc: func(_ context.Context, _, _, _ string) ([]string, error) {
+				return []string{}, nil
+			},
+		}
+		redirectAPI := getNormalisingRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When a redirect is created from a variant of a path", func() {
+			from := "/Economy/./New%2dPath/"
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: testToURL})
+			request := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then it is stored against the normalised path", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(mockStore.SetValueIfAbsentCalls(), ShouldNotBeEmpty)
+				So(mockStore.SetValueIfAbsentCalls()[0].Key, ShouldEqual, "/economy/new-path")
+			})
+		})
+
+		Convey("When a redirect is created to a variant of its own path", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: "/FOO/"})
+			request := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then it is rejected as circular", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrCircularPaths.Error())
+			})
+		})
+
+		Convey("When a redirect is requested by a variant of its path", func() {
+			id := base64.URLEncoding.EncodeToString([]byte("/ECONOMY/old-path/"))
+			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+id, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the redirect stored against the normalised path is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.GetValueCalls()[0].Key, ShouldEqual, redirectFrom)
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.From, ShouldEqual, redirectFrom)
+				So(response.To, ShouldEqual, redirectTo)
+			})
+		})
+
+		Convey("When a redirect is deleted by a variant of its path", func() {
+			id := base64.URLEncoding.EncodeToString([]byte("/economy/OLD-PATH/"))
+			request := httptest.NewRequest(http.MethodDelete, getRedirectBaseURL+id, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the redirect stored against the normalised path is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNoContent)
+				So(mockStore.DeleteValueReturningPreviousCalls(), ShouldHaveLength, 1)
+				So(mockStore.DeleteValueReturningPreviousCalls()[0].Key, ShouldEqual, redirectFrom)
+			})
+		})
+	})
+}
+
+func TestGetCollisions(t *testing.T) {
+	Convey("Given a store containing redirects whose paths collide once normalised", t, func() {
+		mockStore := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
+				return map[string]string{
+					"/economy/old-path":                "/economy/new-path",
+					"/economy/old-path/":               "/economy/newer-path",
+					"/Economy/Old%2dPath":              "/economy/newest-path",
+					"/business":                        "/business/new",
+					"/Census/":                         "/people",
+					"//cy.ons.gov.uk/economy/old-path": "/economi/new-path",
+					`^/economy/old-path/?$`:            `{"to":"/economy/regex","type":"regex"}`,
+				}, 0, nil
+			},
+		}
+		redirectAPI := getNormalisingRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the collisions are requested", func() {
+			request := httptest.NewRequest(http.MethodGet, "http://localhost:29900/v1/maintenance/collisions", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then each group of colliding redirects is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.RedirectCollisions
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+
+				So(response.Count, ShouldEqual, 2)
+				So(response.Items, ShouldHaveLength, 2)
+				So(response.Items[1].Key, ShouldEqual, "/economy/old-path")
+
+				redirects := response.Items[1].Redirects
+				So(redirects, ShouldHaveLength, 3)
+				So(redirects[0].From, ShouldEqual, "/Economy/Old%2dPath")
+				So(redirects[1].From, ShouldEqual, "/economy/old-path")
+				So(redirects[2].From, ShouldEqual, "/economy/old-path/")
+				So(redirects[2].ID, ShouldEqual, encodeBase64("/economy/old-path/"))
+			})
+
+			Convey("And a redirect stored under a key that is not normalised is returned on its own", func() {
+				var response models.RedirectCollisions
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+
+				So(response.Items, ShouldNotBeEmpty)
+				So(response.Items[0].Key, ShouldEqual, "/census")
+				So(response.Items[0].Redirects, ShouldHaveLength, 1)
+				So(response.Items[0].Redirects[0].From, ShouldEqual, "/Census/")
+			})
+		})
+	})
+
+	Convey("Given a store that returns an error", t, func() {
+		mockStore := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
+				return nil, 0, disRedis.ErrKeyNotFound
+			},
+		}
+		redirectAPI := getNormalisingRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the collisions are requested", func() {
+			request := httptest.NewRequest(http.MethodGet, "http://localhost:29900/v1/maintenance/collisions", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 500", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+}
@@ -3,4 +3,15 @@ package api
 const (
 	QueryParameterCount  = "count"
 	QueryParameterCursor = "cursor"
+	QueryParameterHost   = "host"
+	QueryParameterTo     = "to"
+	QueryParameterPath   = "path"
+
+	QueryParameterPrefix   = "prefix"
+	QueryParameterContains = "contains"
+
+	QueryParameterCollapseChains = "collapse_chains"
+	QueryParameterAtomic         = "atomic"
+	QueryParameterFormat         = "format"
+	QueryParameterDryRun         = "dry_run"
 )
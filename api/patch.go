@@ -0,0 +1,190 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// mediaTypeMergePatch is the media type of a JSON merge patch, as defined by RFC 7396
+const mediaTypeMergePatch = "application/merge-patch+json"
+
+// patchRedirect handles the partial update of a redirect with a JSON merge patch. The patch is applied to the
+// stored redirect, and the result is validated and saved in the same way as a redirect given in full.
+func (api *RedirectAPI) patchRedirect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := mux.Vars(r)["id"]
+	logData := log.Data{models.LogRedirectIDKey: id}
+
+	keyDecoded, err := models.ParseRedirectID(id)
+	if err != nil {
+		log.Info(ctx, "invalid base64 id", logData)
+		api.handleError(ctx, w, ErrInvalidBase64Id, http.StatusBadRequest)
+		return
+	}
+	key := api.canonicalKey(keyDecoded)
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mediaTypeMergePatch && mediaType != mediaTypeJSON) {
+			log.Info(ctx, "unsupported content type for a merge patch", log.Data{"content_type": contentType})
+			api.handleError(ctx, w, ErrUnsupportedPatchType, http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	var patch any
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		log.Info(ctx, "invalid merge patch", logData)
+		api.handleError(ctx, w, ErrInvalidRequestBody, http.StatusBadRequest)
+		return
+	}
+	patchFields, ok := patch.(map[string]any)
+	if !ok {
+		log.Info(ctx, "merge patch is not a JSON object", logData)
+		api.handleError(ctx, w, ErrInvalidMergePatch, http.StatusBadRequest)
+		return
+	}
+
+	identity, err := api.getCallerIdentity(r)
+	if err != nil {
+		log.Error(ctx, "failed to get the identity of the caller", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	// the patch is applied again to the latest version of the redirect if it is changed by another request
+	// before the patched redirect is saved
+	conditions := requestPreconditions(r)
+	for attempt := 1; ; attempt++ {
+		redirect, version, status, err := api.patchStoredRedirect(ctx, key, patchFields, conditions)
+		if err != nil {
+			api.handleError(ctx, w, err, status)
+			return
+		}
+
+		conditions.version = version
+		saved, status, err := api.saveRedirect(ctx, redirect, conditions, identity, time.Now().UTC())
+		if errors.Is(err, ErrWriteConflict) && attempt < maxWriteAttempts {
+			continue
+		}
+		if err != nil {
+			api.handleError(ctx, w, err, status)
+			return
+		}
+
+		if saved.longChain {
+			w.Header().Set("Warning", chainWarning)
+		}
+
+		logData = log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+		api.writeRedirect(w, r, http.StatusOK, redirect, logData)
+		return
+	}
+}
+
+// patchStoredRedirect applies a merge patch to the redirect stored against the given key, checking that the
+// stored redirect meets the preconditions and that the patched redirect is valid. The patched redirect is returned
+// with the version of the stored redirect it was patched from, or the status code to report any error with.
+func (api *RedirectAPI) patchStoredRedirect(ctx context.Context, key string, patch map[string]any, conditions preconditions) (*models.Redirect, string, int, error) {
+	logData := log.Data{"key": key}
+	existing, version, err := api.RedirectStore.GetRedirectWithVersion(ctx, key)
+	if err != nil {
+		if errors.Is(err, disRedis.ErrKeyNotFound) {
+			log.Info(ctx, "redirect not found", logData)
+			return nil, "", http.StatusNotFound, ErrNotFound
+		}
+		log.Error(ctx, "redis failed on getting redirect", err, logData)
+		return nil, "", http.StatusInternalServerError, ErrInternal
+	}
+
+	if err := conditions.check(existing); err != nil {
+		log.Info(ctx, "redirect does not meet the preconditions of the request", logData)
+		return nil, "", http.StatusPreconditionFailed, err
+	}
+
+	redirect, err := applyMergePatch(existing, patch)
+	if err != nil {
+		log.Info(ctx, "merge patch gives an invalid redirect", logData)
+		return nil, "", http.StatusBadRequest, ErrInvalidRequestBody
+	}
+
+	logData = log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+	if err := api.validateRedirect(redirect); err != nil {
+		logData["reason"] = err.Error()
+		log.Info(ctx, "invalid redirect", logData)
+		return nil, "", http.StatusBadRequest, err
+	}
+
+	// the host and 'from' path make up the id, so they cannot be changed by a patch
+	if redirect.Key() != key {
+		log.Info(ctx, "patched from field does not match base64 id", logData)
+		return nil, "", http.StatusBadRequest, ErrIDFromMismatch
+	}
+
+	return redirect, version, 0, nil
+}
+
+// applyMergePatch returns the redirect given by applying the fields of a JSON merge patch to an existing
+// redirect. A TTL in the patch replaces the expiry time of the existing redirect, unless the patch also sets it.
+func applyMergePatch(existing *models.Redirect, patch map[string]any) (*models.Redirect, error) {
+	existingJSON, err := json.Marshal(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(existingJSON, &document); err != nil {
+		return nil, err
+	}
+
+	if ttl, ok := patch["ttl"]; ok && ttl != nil {
+		if _, ok := patch["expires_at"]; !ok {
+			delete(document, "expires_at")
+		}
+	}
+
+	patchedJSON, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	var redirect models.Redirect
+	if err := json.Unmarshal(patchedJSON, &redirect); err != nil {
+		return nil, err
+	}
+
+	return &redirect, nil
+}
+
+// mergePatch applies a JSON merge patch to a decoded JSON document, as defined by RFC 7396. Fields set to null
+// in the patch are removed, objects are merged field by field and any other value replaces the original.
+func mergePatch(document, patch any) any {
+	patchFields, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	documentFields, ok := document.(map[string]any)
+	if !ok {
+		documentFields = map[string]any{}
+	}
+
+	for name, value := range patchFields {
+		if value == nil {
+			delete(documentFields, name)
+			continue
+		}
+		documentFields[name] = mergePatch(documentFields[name], value)
+	}
+
+	return documentFields
+}
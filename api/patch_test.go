@@ -0,0 +1,160 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPatchRedirect(t *testing.T) {
+	Convey("Given a stored redirect", t, func() {
+		values := map[string]string{
+			"/economy": `{"to":"/business","status_code":301,"valid_until":"2099-01-01T00:00:00Z","created_at":"2025-01-02T09:30:00Z","created_by":"creator@ons.gov.uk"}`,
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		patchRedirect := func(id, contentType, body string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPatch, "http://localhost:29900/v1/redirects/"+id, strings.NewReader(body))
+			request.Header.Set("Authorization", "Bearer "+testUserToken)
+			if contentType != "" {
+				request.Header.Set("Content-Type", contentType)
+			}
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+		id := encodeBase64("/economy")
+
+		Convey("When a merge patch changes a single field", func() {
+			responseRecorder := patchRedirect(id, "application/merge-patch+json", `{"status_code": 308}`)
+
+			Convey("Then the updated redirect is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var redirect models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &redirect), ShouldBeNil)
+				So(redirect.ID, ShouldEqual, id)
+				So(redirect.StatusCode, ShouldEqual, http.StatusPermanentRedirect)
+				So(redirect.To, ShouldEqual, "/business")
+				So(redirect.ValidUntil, ShouldNotBeNil)
+				So(redirect.Status, ShouldEqual, models.RedirectStatusActive)
+			})
+
+			Convey("And the other fields are kept in the store", func() {
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/economy"]), &stored), ShouldBeNil)
+				So(stored.StatusCode, ShouldEqual, http.StatusPermanentRedirect)
+				So(stored.To, ShouldEqual, "/business")
+				So(stored.CreatedBy, ShouldEqual, "creator@ons.gov.uk")
+				So(stored.UpdatedBy, ShouldEqual, testUserID)
+			})
+		})
+
+		Convey("When a merge patch sets a field to null", func() {
+			responseRecorder := patchRedirect(id, "application/json", `{"valid_until": null}`)
+
+			Convey("Then the field is removed", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values["/economy"]), &stored), ShouldBeNil)
+				So(stored.ValidUntil, ShouldBeNil)
+				So(stored.StatusCode, ShouldEqual, http.StatusMovedPermanently)
+			})
+		})
+
+		Convey("When a merge patch sets a TTL on a redirect with an expiry time", func() {
+			values["/economy"] = `{"to":"/business","expires_at":"2099-01-01T00:00:00Z"}`
+			responseRecorder := patchRedirect(id, "", `{"ttl": 3600}`)
+
+			Convey("Then the TTL replaces the expiry time", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var redirect models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &redirect), ShouldBeNil)
+				So(redirect.TTL, ShouldBeBetweenOrEqual, 3599, 3600)
+				So(redirect.ExpiresAt.Before(time.Now().Add(2*time.Hour)), ShouldBeTrue)
+			})
+		})
+
+		Convey("When the merged redirect is invalid", func() {
+			responseRecorder := patchRedirect(id, "", `{"status_code": 999}`)
+
+			Convey("Then the response status code should be 400 and the redirect is unchanged", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidStatusCode.Error())
+				So(values["/economy"], ShouldContainSubstring, `"status_code":301`)
+			})
+		})
+
+		Convey("When a merge patch removes the target", func() {
+			responseRecorder := patchRedirect(id, "", `{"to": null}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrFromToNotRelative.Error())
+			})
+		})
+
+		Convey("When a merge patch changes the 'from' path", func() {
+			responseRecorder := patchRedirect(id, "", `{"from": "/economy/new"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrIDFromMismatch.Error())
+			})
+		})
+
+		Convey("When a field of the merge patch has the wrong type", func() {
+			responseRecorder := patchRedirect(id, "", `{"status_code": "permanent"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidRequestBody.Error())
+			})
+		})
+
+		Convey("When the merge patch is not a JSON object", func() {
+			responseRecorder := patchRedirect(id, "", `["/business"]`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidMergePatch.Error())
+			})
+		})
+
+		Convey("When the content type is not a merge patch", func() {
+			responseRecorder := patchRedirect(id, "application/json-patch+json", `[{"op": "remove", "path": "/valid_until"}]`)
+
+			Convey("Then the response status code should be 415", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusUnsupportedMediaType)
+			})
+		})
+
+		Convey("When the redirect does not exist", func() {
+			responseRecorder := patchRedirect(encodeBase64("/missing"), "", `{"status_code": 308}`)
+
+			Convey("Then the response status code should be 404", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the id is not valid base64", func() {
+			responseRecorder := patchRedirect("not-base64!", "", `{"status_code": 308}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidBase64Id.Error())
+			})
+		})
+	})
+}
@@ -1,36 +1,40 @@
 package api
 
 import (
-	"encoding/base64"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
 	disRedis "github.com/ONSdigital/dis-redis"
 	"github.com/ONSdigital/dp-net/v2/links"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 )
 
-// getRedirect gets the value of a key from the store
+// getRedirect gets the value of a key from the store, where the key is given by the id in the URL or by the
+// 'path' and 'host' query parameters
 func (api *RedirectAPI) getRedirect(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
-	redirectID := vars["id"]
 
-	decodedString, err := base64.URLEncoding.DecodeString(redirectID)
+	decodedKey, err := api.requestedKey(r)
 	if err != nil {
-		logData := log.Data{models.LogRedirectIDKey: redirectID}
-		log.Info(ctx, "invalid base 64 id", logData)
-		api.handleError(ctx, w, ErrInvalidBase64Id, http.StatusBadRequest)
+		logData := log.Data{models.LogRedirectIDKey: mux.Vars(r)["id"], QueryParameterPath: r.URL.Query().Get(QueryParameterPath), "reason": err.Error()}
+		log.Info(ctx, "invalid redirect requested", logData)
+		api.handleError(ctx, w, err, http.StatusBadRequest)
 		return
 	}
 
-	decodedKey := string(decodedString)
-
 	redirect, err := api.RedirectStore.GetRedirect(ctx, decodedKey)
 	logData := log.Data{"redirect": redirect}
 	if err != nil {
@@ -44,41 +48,47 @@ func (api *RedirectAPI) getRedirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	linkBuilder := links.FromHeadersOrDefault(&r.Header, api.apiURL)
-	redirectHref, err := linkBuilder.BuildLink(fmt.Sprintf("/v1/redirects/%s", redirectID))
-	if err != nil {
-		log.Error(ctx, "redirect builder failed to build link", err, logData)
-		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+	// redirects outside of their validity window are not returned until they become active
+	now := time.Now()
+	if !redirect.IsActive(now) {
+		log.Info(ctx, "redirect is not active", logData)
+		api.handleError(ctx, w, ErrNotFound, http.StatusNotFound)
 		return
 	}
 
-	redirectSelf := models.RedirectSelf{
-		Href: redirectHref,
-		ID:   redirectID,
-	}
-	redirectLinks := models.RedirectLinks{
-		Self: redirectSelf,
+	etag := redirect.ETag()
+	w.Header().Set(HeaderETag, etag)
+	if ifNoneMatch := r.Header.Get(HeaderIfNoneMatch); ifNoneMatch != "" && matchesETag(ifNoneMatch, etag, true) {
+		log.Info(ctx, "redirect not modified", logData)
+		w.WriteHeader(http.StatusNotModified)
+		return
 	}
 
-	responseBody := models.Redirect{
-		From:  decodedKey,
-		To:    redirect,
-		ID:    redirectID,
-		Links: redirectLinks,
+	api.writeRedirect(w, r, http.StatusOK, redirect, logData)
+}
+
+// requestedKey returns the key of the redirect that a request is for, which is given either by the id in the URL
+// or by the 'path' and optional 'host' query parameters. The error returned is the reason the request is invalid.
+func (api *RedirectAPI) requestedKey(r *http.Request) (string, error) {
+	if id, ok := mux.Vars(r)["id"]; ok {
+		key, err := models.ParseRedirectID(id)
+		if err != nil {
+			return "", ErrInvalidBase64Id
+		}
+		return api.canonicalKey(key), nil
 	}
 
-	redirectResponse, err := json.Marshal(responseBody)
-	if err != nil {
-		log.Error(ctx, "failed to marshal response", err, logData)
-		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
-		return
+	host := r.URL.Query().Get(QueryParameterHost)
+	if host != "" && !models.IsValidHost(host) {
+		return "", ErrInvalidHost
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if _, err = w.Write(redirectResponse); err != nil {
-		log.Error(ctx, "failed to write response", err, logData)
-		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+	path := r.URL.Query().Get(QueryParameterPath)
+	if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, models.RegexAnchor) {
+		return "", ErrInvalidRedirectPath
 	}
+
+	return api.canonicalKey(models.RedirectKey(host, path)), nil
 }
 
 // UpsertRedirect handles the creation or update of redirects
@@ -87,13 +97,23 @@ func (api *RedirectAPI) UpsertRedirect(w http.ResponseWriter, r *http.Request) {
 	id := mux.Vars(r)["id"]
 	logData := log.Data{models.LogRedirectIDKey: id}
 
-	fromDecoded, err := base64.URLEncoding.DecodeString(id)
+	fromDecoded, err := models.ParseRedirectID(id)
 	if err != nil {
 		log.Info(ctx, "invalid base64 id", logData)
 		api.handleError(ctx, w, ErrInvalidBase64Id, http.StatusBadRequest)
 		return
 	}
 
+	collapseChains := false
+	if strCollapseChains := r.URL.Query().Get(QueryParameterCollapseChains); strCollapseChains != "" {
+		collapseChains, err = strconv.ParseBool(strCollapseChains)
+		if err != nil {
+			log.Info(ctx, "invalid query parameter - collapse_chains should be a boolean", logData)
+			api.handleError(ctx, w, ErrInvalidCollapseChains, http.StatusBadRequest)
+			return
+		}
+	}
+
 	var redirect models.Redirect
 	if err := json.NewDecoder(r.Body).Decode(&redirect); err != nil {
 		log.Info(ctx, "invalid redirect request")
@@ -101,86 +121,466 @@ func (api *RedirectAPI) UpsertRedirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logData = log.Data{models.LogRedirectFromKey: redirect.From}
-	if redirect.From != string(fromDecoded) {
+	logData = log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+	if err := api.validateRedirect(&redirect); err != nil {
+		logData["reason"] = err.Error()
+		log.Info(ctx, "invalid redirect", logData)
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	// the id of a host scoped redirect includes its host, so that each host has its own set of 'from' paths
+	if redirect.Key() != api.canonicalKey(fromDecoded) {
 		log.Info(ctx, "from field does not match base64 id", logData)
 		api.handleError(ctx, w, ErrIDFromMismatch, http.StatusBadRequest)
 		return
 	}
 
-	logData = log.Data{models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
-	if !isValidRelativePath(redirect.From) || !isValidRelativePath(redirect.To) {
-		log.Info(ctx, "from and to not relative paths", logData)
-		api.handleError(ctx, w, ErrFromToNotRelative, http.StatusBadRequest)
+	identity, err := api.getCallerIdentity(r)
+	if err != nil {
+		log.Error(ctx, "failed to get the identity of the caller", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
 		return
 	}
 
-	// Prevent redirect loops
-	if redirect.From == redirect.To {
-		log.Info(ctx, "'from' and 'to' cannot be the same", logData)
-		api.handleError(ctx, w, ErrCircularPaths, http.StatusBadRequest)
+	now := time.Now().UTC()
+	saved, status, err := api.saveRedirect(ctx, &redirect, requestPreconditions(r), identity, now)
+	if err != nil {
+		// a request to only create the redirect is given the redirect that already exists
+		if errors.Is(err, ErrRedirectExists) && saved != nil && saved.previous != nil {
+			api.handleRedirectExists(w, r, saved.previous, logData)
+			return
+		}
+		api.handleError(ctx, w, err, status)
 		return
 	}
 
+	w.Header().Set(HeaderETag, redirect.ETag())
+	if saved.longChain {
+		w.Header().Set("Warning", chainWarning)
+	}
+
+	// Return appropriate status code
+	status = http.StatusOK // 200 OK — overwritten
+	if saved.previous == nil {
+		status = http.StatusCreated // 201 Created — new key
+
+		if err := api.setLocation(w, r, &redirect); err != nil {
+			log.Error(ctx, "redirect builder failed to build link", err, logData)
+			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if !collapseChains {
+		api.writeRedirect(w, r, status, &redirect, logData)
+		return
+	}
+
+	// Point the redirects that lead to this one straight at the end of its chain. The redirect has already been
+	// saved, so it is still returned if this fails.
+	var report *models.CollapsedRedirects
+	collapsed, err := api.collapseChainsTo(ctx, &redirect, identity, now)
+	extendDeadlines(ctx, w)
+	if err == nil {
+		report, err = api.collapsedReport(r, collapsed)
+	}
+	if err != nil {
+		log.Warn(ctx, "redirect was saved but its redirect chains could not be collapsed", logData, log.FormatErrors([]error{err}))
+		w.Header().Add("Warning", collapseWarning)
+	}
+
+	api.writeCollapsingRedirect(w, r, status, &redirect, report, logData)
+}
+
+// createRedirect handles the creation of a redirect given in the request body, which fails if a redirect from the
+// same path already exists
+func (api *RedirectAPI) createRedirect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var redirect models.Redirect
+	if err := json.NewDecoder(r.Body).Decode(&redirect); err != nil {
+		log.Info(ctx, "invalid redirect request")
+		api.handleError(ctx, w, ErrInvalidRequestBody, http.StatusBadRequest)
+		return
+	}
+
+	logData := log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+	if err := api.validateRedirect(&redirect); err != nil {
+		logData["reason"] = err.Error()
+		log.Info(ctx, "invalid redirect", logData)
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	identity, err := api.getCallerIdentity(r)
+	if err != nil {
+		log.Error(ctx, "failed to get the identity of the caller", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	saved, status, err := api.saveRedirect(ctx, &redirect, preconditions{ifNoneMatch: "*"}, identity, time.Now().UTC())
+	if errors.Is(err, ErrRedirectExists) {
+		log.Info(ctx, "redirect already exists", logData)
+		api.handleError(ctx, w, err, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		api.handleError(ctx, w, err, status)
+		return
+	}
+
+	if saved.longChain {
+		w.Header().Set("Warning", chainWarning)
+	}
+
+	if err := api.setLocation(w, r, &redirect); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	api.writeRedirect(w, r, http.StatusCreated, &redirect, logData)
+}
+
+// setLocation sets the Location header of the response to the URL of the given redirect, for a redirect that
+// the request created
+func (api *RedirectAPI) setLocation(w http.ResponseWriter, r *http.Request, redirect *models.Redirect) error {
+	if err := setRedirectLinks(links.FromHeadersOrDefault(&r.Header, api.apiURL), redirect); err != nil {
+		return err
+	}
+
+	w.Header().Set("Location", redirect.Links.Self.Href)
+	return nil
+}
+
+// validateRedirect checks that the given redirect is valid, normalising its 'from' path and filling in the
+// defaults of any fields that are not given. The error returned is the reason the redirect is invalid.
+func (api *RedirectAPI) validateRedirect(redirect *models.Redirect) error {
+	if redirect.Host != "" && !models.IsValidHost(redirect.Host) {
+		return ErrInvalidHost
+	}
+
+	redirect.From = api.canonicalPath(redirect.From)
+
+	// Regex redirects have a 'from' pattern anchored to the start of the path, which is checked with the type
+	if !isValidRelativePath(strings.TrimPrefix(redirect.From, "^")) {
+		return ErrFromToNotRelative
+	}
+
+	if !isValidRelativePath(redirect.To) && !api.isAllowedExternalTarget(redirect.To) {
+		if strings.Contains(redirect.To, "://") {
+			return ErrExternalHostNotAllowed
+		}
+		return ErrFromToNotRelative
+	}
+
+	if err := validateRedirectType(redirect); err != nil {
+		return err
+	}
+
+	// Prevent redirect loops
+	if redirect.From == api.canonicalPath(redirect.To) {
+		return ErrCircularPaths
+	}
+
+	if redirect.StatusCode == 0 {
+		redirect.StatusCode = models.DefaultStatusCode
+	} else if !models.IsValidStatusCode(redirect.StatusCode) {
+		return ErrInvalidStatusCode
+	}
+
+	if redirect.QueryPolicy == "" {
+		redirect.QueryPolicy = models.DefaultQueryPolicy
+	} else if !models.IsValidQueryPolicy(redirect.QueryPolicy) {
+		return ErrInvalidQueryPolicy
+	}
+
+	if redirect.ValidFrom != nil && redirect.ValidUntil != nil && !redirect.ValidUntil.After(*redirect.ValidFrom) {
+		return ErrInvalidValidityWindow
+	}
+
+	return nil
+}
+
+// savedRedirect describes the outcome of saving a redirect
+type savedRedirect struct {
+	// previous is the redirect that was replaced, or nil if the redirect was created
+	previous *models.Redirect
+	// expiration is how long the redirect was stored for, or zero if it does not expire
+	expiration time.Duration
+	// longChain is true if the redirect creates a chain longer than the maximum depth
+	longChain bool
+}
+
+// maxWriteAttempts is the number of times a redirect is read and written before giving up, when it keeps being
+// changed by other requests between being read and written
+const maxWriteAttempts = 3
+
+// saveRedirect stores a validated redirect, checking that the redirect it replaces meets the preconditions and
+// that it does not create a loop or, when they are rejected, a chain longer than the maximum depth with the
+// redirects already stored. The redirect is only written if the redirect it replaces has not been changed since
+// it was checked, and the checks are repeated if it has, unless the preconditions require a particular version.
+// The status code to report any error returned with is also returned, along with the redirect that failed the
+// preconditions when they are not met.
+func (api *RedirectAPI) saveRedirect(ctx context.Context, redirect *models.Redirect, conditions preconditions, identity string, now time.Time) (*savedRedirect, int, error) {
+	logData := log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+
+	expiration, err := getExpiration(redirect, now)
+	if err != nil {
+		log.Info(ctx, "invalid redirect expiry", logData)
+		return nil, http.StatusBadRequest, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		saved, status, err := api.trySaveRedirect(ctx, redirect, conditions, identity, now, expiration)
+		if !errors.Is(err, store.ErrValueChanged) {
+			return saved, status, err
+		}
+
+		logData["attempt"] = attempt
+		if attempt == maxWriteAttempts || conditions.version != "" {
+			log.Info(ctx, "redirect was changed by another request while being saved", logData)
+			return nil, http.StatusConflict, ErrWriteConflict
+		}
+		log.Info(ctx, "redirect was changed by another request while being saved, retrying", logData)
+	}
+}
+
+// trySaveRedirect makes a single attempt at saving a redirect for saveRedirect. store.ErrValueChanged is
+// returned if the redirect it replaces was changed by another request before it could be written.
+// When the redirect it would replace does not meet the preconditions, that redirect is returned as the previous
+// redirect along with the error.
+func (api *RedirectAPI) trySaveRedirect(ctx context.Context, redirect *models.Redirect, conditions preconditions, identity string, now time.Time, expiration time.Duration) (*savedRedirect, int, error) {
+	logData := log.Data{models.LogRedirectHostKey: redirect.Host, models.LogRedirectFromKey: redirect.From, models.LogRedirectToKey: redirect.To}
+	saved := &savedRedirect{expiration: expiration}
+
 	// Check if the redirect already exists but if not then create it
-	existingValue, err := api.RedirectStore.GetValue(ctx, redirect.From)
-	logData = log.Data{"existingValue": existingValue}
+	previous, version, err := api.RedirectStore.GetRedirectWithVersion(ctx, redirect.Key())
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			// log the error but then continue and create new redirect
-			log.Info(ctx, "redirect not found so creating new one", logData)
-		} else {
+		if !errors.Is(err, disRedis.ErrKeyNotFound) {
 			log.Error(ctx, "redis failed on checking redirect existence", err, logData)
-			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
-			return
+			return nil, http.StatusInternalServerError, ErrInternal
 		}
+		// log the error but then continue and create new redirect
+		log.Info(ctx, "redirect not found so creating new one", logData)
+	}
+	saved.previous = previous
+
+	if conditions.version != "" && conditions.version != version {
+		return nil, http.StatusConflict, store.ErrValueChanged
 	}
 
-	err = api.RedirectStore.UpsertValue(ctx, redirect.From, redirect.To, 0)
+	if err := conditions.check(saved.previous); err != nil {
+		log.Info(ctx, "redirect does not meet the preconditions of the request", logData)
+		return saved, http.StatusPreconditionFailed, err
+	}
+
+	// Prevent loops and long chains through existing redirects
+	depth, err := api.getChainDepth(ctx, redirect, now)
 	if err != nil {
+		if errors.Is(err, ErrRedirectLoop) {
+			log.Info(ctx, "redirect would create a loop", logData)
+			return nil, http.StatusBadRequest, ErrRedirectLoop
+		}
+		log.Error(ctx, "redis failed on following redirect chain", err, logData)
+		return nil, http.StatusInternalServerError, ErrInternal
+	}
+
+	// the redirects leading to this one are part of the same chain
+	upstream, err := api.getUpstreamDepth(ctx, redirect, now)
+	if err != nil {
+		log.Error(ctx, "redis failed on following the redirects leading to redirect", err, logData)
+		return nil, http.StatusInternalServerError, ErrInternal
+	}
+	depth += upstream
+
+	if depth > api.chainMaxDepth {
+		logData["chain_depth"] = depth
+		if api.rejectLongChains {
+			log.Info(ctx, "redirect chain exceeds maximum depth", logData)
+			return nil, http.StatusBadRequest, ErrRedirectChainTooLong
+		}
+		log.Warn(ctx, "redirect chain exceeds maximum depth", logData)
+		saved.longChain = true
+	}
+
+	redirect.UpdatedAt = &now
+	redirect.UpdatedBy = identity
+	if saved.previous == nil {
+		redirect.CreatedAt = &now
+		redirect.CreatedBy = identity
+		err = api.RedirectStore.CreateRedirect(ctx, redirect, expiration)
+	} else {
+		redirect.CreatedAt = saved.previous.CreatedAt
+		redirect.CreatedBy = saved.previous.CreatedBy
+		err = api.RedirectStore.UpdateRedirect(ctx, redirect, version, expiration)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrValueChanged) {
+			return nil, http.StatusConflict, err
+		}
 		log.Error(ctx, "redis failed on upserting redirect", err, logData)
+		return nil, http.StatusInternalServerError, ErrInternal
+	}
+
+	return saved, 0, nil
+}
+
+// writeRedirect writes the given redirect as the response body with the given status code, along with its ETag,
+// setting its id, links, remaining TTL and activation status first
+func (api *RedirectAPI) writeRedirect(w http.ResponseWriter, req *http.Request, status int, redirect *models.Redirect, logData log.Data) {
+	ctx := req.Context()
+	w.Header().Set(HeaderETag, redirect.ETag())
+
+	if err := api.setResponseFields(req, redirect); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
 		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
 		return
 	}
 
-	// Return appropriate status code
-	if existingValue == "" {
-		w.WriteHeader(http.StatusCreated) // 201 Created — new key
-	} else {
-		w.WriteHeader(http.StatusOK) // 200 OK — overwritten
+	redirectResponse, err := json.Marshal(redirect)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(redirectResponse); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+	}
+}
+
+// writeCollapsingRedirect writes the given redirect as the response body with the given status code, along with the
+// report of the redirects changed by collapsing the chains leading to it, if there is one
+func (api *RedirectAPI) writeCollapsingRedirect(w http.ResponseWriter, req *http.Request, status int, redirect *models.Redirect, report *models.CollapsedRedirects, logData log.Data) {
+	ctx := req.Context()
+	w.Header().Set(HeaderETag, redirect.ETag())
+
+	if err := api.setResponseFields(req, redirect); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	redirectResponse, err := json.Marshal(models.CollapsingRedirect{Redirect: *redirect, Collapsed: report})
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(redirectResponse); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+	}
+}
+
+// handleRedirectExists writes the redirect_exists error with a 412 status for a request to only create a redirect,
+// along with the redirect that already exists and its ETag
+func (api *RedirectAPI) handleRedirectExists(w http.ResponseWriter, req *http.Request, existing *models.Redirect, logData log.Data) {
+	ctx := req.Context()
+	w.Header().Set(HeaderETag, existing.ETag())
+
+	if err := api.setResponseFields(req, existing); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	log.Info(ctx, "redirect already exists", logData)
+	errorResponse := models.ErrorResponse{
+		Code:      errorCode(ErrRedirectExists, http.StatusPreconditionFailed),
+		Message:   ErrRedirectExists.Error(),
+		RequestID: dprequest.GetRequestId(ctx),
+		Existing:  existing,
 	}
+	writeErrorResponse(ctx, w, errorResponse, http.StatusPreconditionFailed)
+}
+
+// setResponseFields sets the fields of a redirect that are worked out when it is returned: its id, links,
+// remaining TTL and activation status
+func (api *RedirectAPI) setResponseFields(req *http.Request, redirect *models.Redirect) error {
+	linkBuilder := links.FromHeadersOrDefault(&req.Header, api.apiURL)
+	if err := setRedirectLinks(linkBuilder, redirect); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	redirect.TTL = redirect.RemainingTTL(now)
+	redirect.Status = redirect.ActivationStatus(now)
+	return nil
 }
 
-// DeleteRedirect handles the deletion of a redirect
+// DeleteRedirect handles the deletion of a redirect, given by the id in the URL or by the 'path' and 'host' query
+// parameters
 func (api *RedirectAPI) DeleteRedirect(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	id := mux.Vars(r)["id"]
-	logData := log.Data{"redirect_id": id}
 
-	keyBytes, err := base64.URLEncoding.DecodeString(id)
+	key, err := api.requestedKey(r)
 	if err != nil {
-		log.Info(ctx, "invalid base 64 id", logData)
-		api.handleError(ctx, w, ErrInvalidBase64Id, http.StatusBadRequest)
+		logData := log.Data{"redirect_id": mux.Vars(r)["id"], QueryParameterPath: r.URL.Query().Get(QueryParameterPath), "reason": err.Error()}
+		log.Info(ctx, "invalid redirect requested", logData)
+		api.handleError(ctx, w, err, http.StatusBadRequest)
 		return
 	}
-	key := string(keyBytes)
 
-	// Check if the key exists
-	logData = log.Data{"key": key}
-	_, err = api.RedirectStore.GetValue(ctx, key)
-	if err != nil {
-		if err == disRedis.ErrKeyNotFound {
+	// Delete the redirect if it exists
+	logData := log.Data{"key": key}
+
+	conditions := requestPreconditions(r)
+	if conditions.conditional() {
+		api.deleteRedirectIfMatched(w, r, key, conditions)
+		return
+	}
+
+	if err := api.RedirectStore.DeleteRedirect(ctx, key); err != nil {
+		if errors.Is(err, disRedis.ErrKeyNotFound) {
 			log.Info(ctx, "redirect not found", logData)
 			api.handleError(ctx, w, ErrNotFound, http.StatusNotFound)
 			return
 		}
-		log.Error(ctx, "redis failed on checking redirect existence", err, logData)
+		log.Error(ctx, "redis failed on deleting redirect", err, logData)
 		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
 		return
 	}
 
-	// Proceed to delete
-	if err := api.RedirectStore.DeleteValue(ctx, key); err != nil {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteRedirectIfMatched deletes the redirect stored against the given key for a conditional request, but only
+// if the redirect meets the preconditions and is not changed by another request before it is deleted
+func (api *RedirectAPI) deleteRedirectIfMatched(w http.ResponseWriter, r *http.Request, key string, conditions preconditions) {
+	ctx := r.Context()
+	logData := log.Data{"key": key}
+
+	current, version, err := api.RedirectStore.GetRedirectWithVersion(ctx, key)
+	if err != nil && !errors.Is(err, disRedis.ErrKeyNotFound) {
+		log.Error(ctx, "redis failed on getting redirect", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+	if err := conditions.check(current); err != nil {
+		log.Info(ctx, "redirect does not meet the preconditions of the request", logData)
+		api.handleError(ctx, w, err, http.StatusPreconditionFailed)
+		return
+	}
+	if current == nil {
+		log.Info(ctx, "redirect not found", logData)
+		api.handleError(ctx, w, ErrNotFound, http.StatusNotFound)
+		return
+	}
+
+	if err := api.RedirectStore.DeleteRedirectIfUnchanged(ctx, key, version); err != nil {
+		if errors.Is(err, store.ErrValueChanged) {
+			log.Info(ctx, "redirect was changed by another request before it could be deleted", logData)
+			api.handleError(ctx, w, ErrPreconditionFailed, http.StatusPreconditionFailed)
+			return
+		}
 		log.Error(ctx, "redis failed on deleting redirect", err, logData)
 		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
 		return
@@ -189,15 +589,150 @@ func (api *RedirectAPI) DeleteRedirect(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// validateRedirectType sets the type of the redirect from its 'from' path when one is not given, and checks
+// that any wildcards in the 'from' and 'to' paths are valid for that type. The patterns of regex redirects are
+// compiled to check that they are valid and not too complex, and that the 'to' path only refers to groups
+// they capture.
+func validateRedirectType(redirect *models.Redirect) error {
+	isPrefix := strings.HasSuffix(redirect.From, models.PrefixWildcard)
+	if redirect.Type == "" {
+		switch {
+		case strings.HasPrefix(redirect.From, "^"):
+			redirect.Type = models.RedirectTypeRegex
+		case isPrefix:
+			redirect.Type = models.RedirectTypePrefix
+		default:
+			redirect.Type = models.RedirectTypeExact
+		}
+	}
+
+	switch redirect.Type {
+	case models.RedirectTypeExact:
+		if !isValidRelativePath(redirect.From) {
+			return ErrFromToNotRelative
+		}
+		if strings.Contains(redirect.From, "*") || strings.Contains(redirect.To, "*") {
+			return ErrUnexpectedWildcard
+		}
+	case models.RedirectTypePrefix:
+		if !isValidRelativePath(redirect.From) {
+			return ErrFromToNotRelative
+		}
+		if !isPrefix ||
+			strings.Contains(strings.TrimSuffix(redirect.From, models.PrefixWildcard), "*") ||
+			strings.Contains(strings.TrimSuffix(redirect.To, models.PrefixWildcard), "*") {
+			return ErrInvalidPrefixRedirect
+		}
+	case models.RedirectTypeRegex:
+		if !strings.HasPrefix(redirect.From, models.RegexAnchor) {
+			return ErrInvalidRegexRedirect
+		}
+		re, err := models.CompilePattern(redirect.From)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRegexPattern, err.Error())
+		}
+		if err := models.ValidateSubstitution(re, redirect.To); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRegexPattern, err.Error())
+		}
+	default:
+		return ErrInvalidRedirectType
+	}
+
+	return nil
+}
+
+// getExpiration validates the optional 'ttl' and 'expires_at' fields of the redirect, setting 'expires_at'
+// from the ttl when one is given, and returns how long the redirect should be kept for. A zero duration
+// means that the redirect does not expire. Redirects are returned with both fields, so a ttl given alongside
+// 'expires_at' is ignored when it is the time that was left until 'expires_at' at some point up to now, as it
+// is when a redirect that was read is written back unchanged.
+func getExpiration(redirect *models.Redirect, now time.Time) (time.Duration, error) {
+	if redirect.TTL < 0 {
+		return 0, ErrInvalidTTL
+	}
+
+	if redirect.TTL > 0 && redirect.ExpiresAt != nil {
+		if redirect.ExpiresAt.Add(-time.Duration(redirect.TTL) * time.Second).After(now) {
+			return 0, ErrTTLAndExpiresAt
+		}
+		redirect.TTL = 0
+	}
+
+	if redirect.TTL > 0 {
+		expiresAt := now.Add(time.Duration(redirect.TTL) * time.Second)
+		redirect.ExpiresAt = &expiresAt
+		redirect.TTL = 0
+	}
+
+	if redirect.ExpiresAt == nil {
+		return 0, nil
+	}
+
+	if !redirect.ExpiresAt.After(now) {
+		return 0, ErrExpiresAtInPast
+	}
+
+	return redirect.ExpiresAt.Sub(now), nil
+}
+
 func isValidRelativePath(path string) bool {
-	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//")
+	// browsers treat a backslash after the leading slash as another slash, making the path protocol relative
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "/\\")
+}
+
+// isValidPrefix returns true if the given prefix is empty or could be the start of a 'from' path or pattern
+func isValidPrefix(prefix string) bool {
+	return prefix == "" || strings.HasPrefix(prefix, "/") || strings.HasPrefix(prefix, models.RegexAnchor)
 }
 
-// encodeBase64 returns the base64 encoded string of the original URL key string
-func encodeBase64(key string) string {
-	encodedKey := base64.StdEncoding.EncodeToString([]byte(key))
+// isAllowedExternalTarget returns true if the given target is an absolute URL using an allowed scheme on an
+// allowed external host. Allowed hosts starting with "*." also allow any subdomain of the host that follows.
+// Targets containing credentials, backslashes, whitespace or control characters are never allowed, as
+// browsers can interpret these differently to the host that is checked here.
+func (api *RedirectAPI) isAllowedExternalTarget(target string) bool {
+	if strings.ContainsAny(target, "\\ ") || strings.ContainsFunc(target, unicode.IsControl) {
+		return false
+	}
+
+	targetURL, err := url.Parse(target)
+	if err != nil || targetURL.Opaque != "" || targetURL.User != nil || targetURL.Host == "" {
+		return false
+	}
 
-	return encodedKey
+	if !slices.Contains(api.externalSchemes, strings.ToLower(targetURL.Scheme)) {
+		return false
+	}
+
+	host := strings.ToLower(targetURL.Hostname())
+	for _, allowedHost := range api.externalHosts {
+		if host == allowedHost {
+			return true
+		}
+		if parent, ok := strings.CutPrefix(allowedHost, "*."); ok && strings.HasSuffix(host, "."+parent) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setRedirectLinks sets the id of the redirect and the link to itself
+func setRedirectLinks(linkBuilder *links.Builder, redirect *models.Redirect) error {
+	redirectID := models.RedirectID(redirect.Key())
+	redirectHref, err := linkBuilder.BuildLink(fmt.Sprintf("/v1/redirects/%s", redirectID))
+	if err != nil {
+		return err
+	}
+
+	redirect.ID = redirectID
+	redirect.Links = models.RedirectLinks{
+		Self: models.RedirectSelf{
+			Href: redirectHref,
+			ID:   redirectID,
+		},
+	}
+
+	return nil
 }
 
 // getRedirects gets a paged list of redirects from the store
@@ -241,49 +776,60 @@ func (api *RedirectAPI) getRedirects(w http.ResponseWriter, req *http.Request) {
 		api.handleError(ctx, w, ErrInvalidOrNegativeCursor, http.StatusBadRequest)
 		return
 	}
-	logData = log.Data{QueryParameterCount: count, QueryParameterCursor: cursor}
 
-	keyValuePairs, newCursor, err := api.RedirectStore.GetRedirects(ctx, count, cursor)
+	host := req.URL.Query().Get(QueryParameterHost)
+	if host != "" && !models.IsValidHost(host) {
+		log.Info(ctx, "invalid query parameter - host should be a lowercase hostname", logData)
+		api.handleError(ctx, w, ErrInvalidHost, http.StatusBadRequest)
+		return
+	}
+	prefix := req.URL.Query().Get(QueryParameterPrefix)
+	if !isValidPrefix(prefix) {
+		log.Info(ctx, "invalid query parameter - prefix should start with a '/' or the regex anchor", logData)
+		api.handleError(ctx, w, ErrInvalidPrefix, http.StatusBadRequest)
+		return
+	}
+
+	filter := store.RedirectFilter{
+		Host:     host,
+		Prefix:   prefix,
+		Contains: req.URL.Query().Get(QueryParameterContains),
+	}
+	to := req.URL.Query().Get(QueryParameterTo)
+	logData = log.Data{
+		QueryParameterCount:    count,
+		QueryParameterCursor:   cursor,
+		QueryParameterHost:     host,
+		QueryParameterPrefix:   filter.Prefix,
+		QueryParameterContains: filter.Contains,
+		QueryParameterTo:       to,
+	}
+
+	if to != "" {
+		api.getRedirectsTo(w, req, filter, to)
+		return
+	}
+
+	redirectList, newCursor, err := api.RedirectStore.GetRedirects(ctx, filter, count, cursor)
 	if err != nil {
 		log.Error(ctx, "redis failed on getting redirects", err, logData)
 		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
 		return
 	}
 
-	logData = log.Data{"num_redirects": len(keyValuePairs)}
+	logData = log.Data{"num_redirects": len(redirectList)}
 	log.Info(ctx, "redirects retrieved from redis", logData)
 
-	redirectList := make([]models.Redirect, 0, len(keyValuePairs))
-
-	linkBuilder := links.FromHeadersOrDefault(&req.Header, api.apiURL)
-
-	for key, value := range keyValuePairs {
-		var redirect models.Redirect
-		redirectID := encodeBase64(key)
-		redirectHref, err := linkBuilder.BuildLink(fmt.Sprintf("/v1/redirects/%s", redirectID))
-		if err != nil {
-			log.Error(ctx, "redirect builder failed to build link", err, logData)
-			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
-			return
-		}
-		redirectSelf := models.RedirectSelf{
-			Href: redirectHref,
-			ID:   redirectID,
-		}
-		redirectLinks := models.RedirectLinks{
-			Self: redirectSelf,
-		}
-		redirect.From = key
-		redirect.To = value
-		redirect.ID = redirectID
-		redirect.Links = redirectLinks
-		redirectList = append(redirectList, redirect)
+	if err := api.setRedirectListFields(req, redirectList); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
 	}
 
 	nextCursor := strconv.FormatUint(newCursor, 10)
 
-	// To get the TotalCount we need to get the total number of redirects available in redis
-	totalCount, errTotalCount := api.RedirectStore.GetTotalCount(ctx)
+	// To get the TotalCount we need to get the total number of redirects selected by the filter in redis
+	totalCount, errTotalCount := api.RedirectStore.GetTotalCount(ctx, filter)
 	if errTotalCount != nil {
 		log.Error(ctx, "redis failed on getting total count of redirects", errTotalCount, logData)
 		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
@@ -298,6 +844,75 @@ func (api *RedirectAPI) getRedirects(w http.ResponseWriter, req *http.Request) {
 		TotalCount:   totalCount,
 	}
 
+	api.writeRedirects(w, req, responseBody, logData)
+}
+
+// getRedirectsTo handles the listing of every redirect that lands on the given target, using the reverse index
+// rather than paging through the store. Only the redirects selected by the filter are listed.
+func (api *RedirectAPI) getRedirectsTo(w http.ResponseWriter, req *http.Request, filter store.RedirectFilter, to string) {
+	ctx := req.Context()
+	logData := log.Data{
+		QueryParameterHost:     filter.Host,
+		QueryParameterPrefix:   filter.Prefix,
+		QueryParameterContains: filter.Contains,
+		QueryParameterTo:       to,
+	}
+
+	redirects, err := api.RedirectStore.GetRedirectsTo(ctx, to)
+	if err != nil {
+		log.Error(ctx, "redis failed on getting redirects to target", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	redirectList := make([]models.Redirect, 0, len(redirects))
+	for i := range redirects {
+		if filter.Matches(redirects[i].Key()) {
+			redirectList = append(redirectList, redirects[i])
+		}
+	}
+
+	logData["num_redirects"] = len(redirectList)
+	log.Info(ctx, "redirects to target retrieved from redis", logData)
+
+	if err := api.setRedirectListFields(req, redirectList); err != nil {
+		log.Error(ctx, "redirect builder failed to build link", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	responseBody := models.Redirects{
+		Count:        len(redirectList),
+		RedirectList: redirectList,
+		Cursor:       "0",
+		NextCursor:   "0",
+		TotalCount:   len(redirectList),
+	}
+
+	api.writeRedirects(w, req, responseBody, logData)
+}
+
+// setRedirectListFields sets the id, links, remaining TTL and activation status of each redirect in the list
+func (api *RedirectAPI) setRedirectListFields(req *http.Request, redirectList []models.Redirect) error {
+	linkBuilder := links.FromHeadersOrDefault(&req.Header, api.apiURL)
+	now := time.Now()
+
+	for i := range redirectList {
+		redirect := &redirectList[i]
+		if err := setRedirectLinks(linkBuilder, redirect); err != nil {
+			return err
+		}
+		redirect.TTL = redirect.RemainingTTL(now)
+		redirect.Status = redirect.ActivationStatus(now)
+	}
+
+	return nil
+}
+
+// writeRedirects writes the given list of redirects as the response
+func (api *RedirectAPI) writeRedirects(w http.ResponseWriter, req *http.Request, responseBody models.Redirects, logData log.Data) {
+	ctx := req.Context()
+
 	redirectsResponse, err := json.Marshal(responseBody)
 	if err != nil {
 		log.Error(ctx, "failed to marshal response", err, logData)
@@ -18,6 +18,7 @@ import (
 	"github.com/ONSdigital/dis-redirect-api/store"
 	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
 	disRedis "github.com/ONSdigital/dis-redis"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -53,7 +54,10 @@ var (
 			return keyValuePairs, 0, nil
 		},
 		GetTotalKeysFunc: func(_ context.Context) (int64, error) {
-			return 12, nil
+			return 5, nil
+		},
+		CountExistingKeysFunc: func(_ context.Context, keys ...string) (int64, error) {
+			return int64(len(keys)), nil
 		},
 		GetValueFunc: func(_ context.Context, _ string) (string, error) {
 			return redirectTo, nil
@@ -61,9 +65,9 @@ var (
 	}
 )
 
-// encodeBase64 returns the base64 encoded string of the original URL key string
+// encodeBase64 returns the URL safe base64 encoded string of the original URL key string
 func encodeBase64(key string) string {
-	encodedKey := base64.StdEncoding.EncodeToString([]byte(key))
+	encodedKey := base64.URLEncoding.EncodeToString([]byte(key))
 
 	return encodedKey
 }
@@ -105,6 +109,79 @@ func TestGetRedirectEndpoint(t *testing.T) {
 				So(response.ID, ShouldEqual, existingBase64Key)
 				So(response.Links.Self.ID, ShouldEqual, existingBase64Key)
 				So(response.Links.Self.Href, ShouldEqual, selfBaseURL+existingBase64Key)
+				So(response.StatusCode, ShouldEqual, http.StatusMovedPermanently)
+				So(response.QueryPolicy, ShouldEqual, models.DefaultQueryPolicy)
+			})
+		})
+
+		Convey("When the redirect is stored with an expiry time", func() {
+			expiresAt := time.Now().UTC().Add(90 * time.Second).Format(time.RFC3339Nano)
+			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+existingBase64Key, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+
+			mockStore := &storetest.StorerMock{
+				GetValueFunc: func(_ context.Context, _ string) (string, error) {
+					return `{"to":"` + redirectTo + `","expires_at":"` + expiresAt + `"}`, nil
+				},
+			}
+
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response should contain the expiry time and the remaining lifetime", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirect
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+
+				So(response.ExpiresAt, ShouldNotBeNil)
+				So(response.TTL, ShouldBeBetweenOrEqual, 89, 90)
+			})
+		})
+
+		Convey("When the redirect is scheduled to start in the future", func() {
+			validFrom := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+existingBase64Key, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+
+			mockStore := &storetest.StorerMock{
+				GetValueFunc: func(_ context.Context, _ string) (string, error) {
+					return `{"to":"` + redirectTo + `","valid_from":"` + validFrom + `"}`, nil
+				},
+			}
+
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 404", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the redirect is stored with a status code", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+existingBase64Key, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+
+			mockStore := &storetest.StorerMock{
+				GetValueFunc: func(_ context.Context, _ string) (string, error) {
+					return `{"to":"` + redirectTo + `","status_code":307}`, nil
+				},
+			}
+
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response should contain the stored status code", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirect
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+
+				So(response.From, ShouldEqual, validRedirect.From)
+				So(response.To, ShouldEqual, validRedirect.To)
+				So(response.StatusCode, ShouldEqual, http.StatusTemporaryRedirect)
 			})
 		})
 	})
@@ -135,13 +212,13 @@ func TestGetRedirectURLWriting(t *testing.T) {
 func TestGetRedirectReturns400(t *testing.T) {
 	Convey("Given a GET /redirects/{id} request", t, func() {
 		Convey("When the id is not endcoded in base64", func() {
-			var nonBase64Key = "some-string"
+			var nonBase64Key = "some*string"
 			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+nonBase64Key, http.NoBody)
 			responseRecorder := httptest.NewRecorder()
 
 			mockStore := &storetest.StorerMock{
 				GetValueFunc: func(_ context.Context, _ string) (string, error) {
-					return "", errors.New("key some-string not base64")
+					return "", errors.New("key some*string not base64")
 				},
 			}
 
@@ -150,6 +227,18 @@ func TestGetRedirectReturns400(t *testing.T) {
 
 			Convey("Then the response status code should be 400", func() {
 				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+
+				Convey("And the error is described in a JSON body", func() {
+					So(responseRecorder.Header().Get("Content-Type"), ShouldEqual, "application/json")
+
+					var response models.ErrorResponse
+					err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+					So(err, ShouldBeNil)
+					So(response, ShouldResemble, models.ErrorResponse{
+						Code:    models.ErrorCodeInvalidID,
+						Message: api.ErrInvalidBase64Id.Error(),
+					})
+				})
 			})
 		})
 	})
@@ -160,6 +249,7 @@ func TestGetRedirectReturns404(t *testing.T) {
 		Convey("When the id is valid and encoded in base64", func() {
 			var nonExistentBase64Key = "b2xkLXBhdGg="
 			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+nonExistentBase64Key, http.NoBody)
+			request = request.WithContext(dprequest.WithRequestId(request.Context(), "test-request-id"))
 			responseRecorder := httptest.NewRecorder()
 
 			mockStore := &storetest.StorerMock{
@@ -173,6 +263,14 @@ func TestGetRedirectReturns404(t *testing.T) {
 
 			Convey("Then the response status code should be 404", func() {
 				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+
+				Convey("And the error response includes the ID of the request", func() {
+					var response models.ErrorResponse
+					err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+					So(err, ShouldBeNil)
+					So(response.Code, ShouldEqual, models.ErrorCodeNotFound)
+					So(response.RequestID, ShouldEqual, "test-request-id")
+				})
 			})
 		})
 	})
@@ -225,6 +323,9 @@ func TestGetRedirectsSuccessWithDefaultParams(t *testing.T) {
 				GetTotalKeysFunc: func(_ context.Context) (int64, error) {
 					return 12, nil
 				},
+				CountExistingKeysFunc: func(_ context.Context, keys ...string) (int64, error) {
+					return int64(len(keys)), nil
+				},
 			}
 
 			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
@@ -251,7 +352,7 @@ func TestGetRedirectsSuccessWithDefaultParams(t *testing.T) {
 				SkipSo(respItem1.Links.Self.Href, ShouldEqual, getRedirectBaseURL+expectedID) // TODO change this back to 'So' when the URL rewriting functionality is fixed
 				So(response.Cursor, ShouldEqual, "0")
 				So(response.NextCursor, ShouldEqual, "0")
-				So(response.TotalCount, ShouldEqual, 12)
+				So(response.TotalCount, ShouldEqual, 10)
 			})
 		})
 	})
@@ -265,14 +366,26 @@ func TestUpsertRedirect(t *testing.T) {
 				case "/old-url":
 					return "http://localhost:8081/new-url", nil
 				case nonRedirectURL:
-					return "", nil
+					return "", disRedis.ErrKeyNotFound
 				default:
-					return "", nil
+					return "", disRedis.ErrKeyNotFound
 				}
 			},
 			SetValueFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
 				return nil
 			},
+			SetValueIfAbsentFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+				return true, nil
+			},
+			AddToSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			RemoveFromSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			GetSortedSetRangeByLexFunc: func(_ context.Context, _, _, _ string) ([]string, error) {
+				return []string{}, nil
+			},
 		}
 
 		apiInstance := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
@@ -294,181 +407,1263 @@ func TestUpsertRedirect(t *testing.T) {
 			apiInstance.UpsertRedirect(rec, req)
 
 			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
-			So(mockStore.SetValueCalls()[0].Key, ShouldEqual, from)
+			So(mockStore.SetValueIfAbsentCalls()[0].Key, ShouldEqual, from)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.To, ShouldEqual, to)
+			So(stored.Type, ShouldEqual, models.RedirectTypeExact)
+			So(stored.StatusCode, ShouldEqual, http.StatusMovedPermanently)
+
+			var response models.Redirect
+			So(json.Unmarshal(rec.Body.Bytes(), &response), ShouldBeNil)
+			So(response.From, ShouldEqual, from)
+			So(response.To, ShouldEqual, to)
+			So(response.ID, ShouldEqual, id)
+			So(response.Links.Self.Href, ShouldEqual, selfBaseURL+id)
+			So(rec.Header().Get("Location"), ShouldEqual, selfBaseURL+id)
+			So(rec.Header().Get(api.HeaderETag), ShouldEqual, response.ETag())
 		})
 
-		Convey("When ID is not valid base64", func() {
-			req := httptest.NewRequest(http.MethodPut, "/redirects/!badid", bytes.NewBuffer([]byte(`{}`)))
-			req = mux.SetURLVars(req, map[string]string{idKey: "!badid"})
+		Convey("When a new redirect is created by an identified user", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer "+testUserToken)
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
 			rec := httptest.NewRecorder()
 
 			apiInstance.UpsertRedirect(rec, req)
 
-			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.CreatedBy, ShouldEqual, testUserID)
+			So(stored.UpdatedBy, ShouldEqual, testUserID)
+			So(stored.CreatedAt, ShouldNotBeNil)
+			So(*stored.UpdatedAt, ShouldEqual, *stored.CreatedAt)
 		})
 
-		Convey("When base64 decodes but doesn't match body.from", func() {
-			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
-			redirect := models.Redirect{
-				From: "/mismatch",
-				To:   testToURL,
+		Convey("When an existing redirect is updated by an identified user", func() {
+			createdAt := time.Date(2025, time.January, 2, 9, 30, 0, 0, time.UTC)
+			existing := `{"to":"/old-target","created_at":"2025-01-02T09:30:00Z","created_by":"creator@ons.gov.uk"}`
+			mockStore.GetValueFunc = func(_ context.Context, key string) (string, error) {
+				if key != testFromURL {
+					return "", disRedis.ErrKeyNotFound
+				}
+				return existing, nil
 			}
-			body, _ := json.Marshal(redirect)
+			mockStore.CompareAndSwapValueFunc = func(_ context.Context, _, _ string, _ interface{}, _ time.Duration) error {
+				return nil
+			}
+
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL})
 			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req.Header.Set("Authorization", "Bearer "+testUserToken)
 			req = mux.SetURLVars(req, map[string]string{idKey: id})
 			rec := httptest.NewRecorder()
 
 			apiInstance.UpsertRedirect(rec, req)
 
-			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusOK)
+			So(mockStore.SetValueIfAbsentCalls(), ShouldBeEmpty)
+			So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 1)
+			So(mockStore.CompareAndSwapValueCalls()[0].Expected, ShouldEqual, existing)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.CompareAndSwapValueCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.To, ShouldEqual, testToURL)
+			So(*stored.CreatedAt, ShouldEqual, createdAt)
+			So(stored.CreatedBy, ShouldEqual, "creator@ons.gov.uk")
+			So(stored.UpdatedAt.After(createdAt), ShouldBeTrue)
+			So(stored.UpdatedBy, ShouldEqual, testUserID)
+
+			var response models.Redirect
+			So(json.Unmarshal(rec.Body.Bytes(), &response), ShouldBeNil)
+			So(response.To, ShouldEqual, testToURL)
+			So(response.CreatedBy, ShouldEqual, "creator@ons.gov.uk")
+			So(response.ID, ShouldEqual, id)
+			So(rec.Header().Get("Location"), ShouldBeEmpty)
 		})
 
-		Convey("When Redis returns an error", func() {
-			mockStore.SetValueFunc = func(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
-				return errors.New("redis error")
-			}
+		Convey("When request is valid and contains a ttl", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, TTL: 3600})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			from := testFromURL
-			id := base64.URLEncoding.EncodeToString([]byte(from))
-			redirect := models.Redirect{
-				From: from,
-				To:   testToURL,
-			}
-			body, _ := json.Marshal(redirect)
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			So(mockStore.SetValueIfAbsentCalls()[0].Expiration, ShouldEqual, time.Hour)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.ExpiresAt, ShouldNotBeNil)
+			So(stored.TTL, ShouldEqual, 0)
+		})
+
+		Convey("When request is valid and contains an expiry time", func() {
+			expiresAt := time.Now().UTC().Add(48 * time.Hour)
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, ExpiresAt: &expiresAt})
 			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
 			req = mux.SetURLVars(req, map[string]string{idKey: id})
 			rec := httptest.NewRecorder()
 
 			apiInstance.UpsertRedirect(rec, req)
 
-			So(rec.Result().StatusCode, ShouldEqual, http.StatusInternalServerError)
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			So(mockStore.SetValueIfAbsentCalls()[0].Expiration, ShouldBeBetween, 47*time.Hour, 48*time.Hour)
 		})
 
-		Convey("When request body is invalid JSON", func() {
-			id := base64.URLEncoding.EncodeToString([]byte("/foo"))
-			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer([]byte(`{bad json`)))
+		Convey("When a ttl that does not agree with the expiry time is provided", func() {
+			expiresAt := time.Now().UTC().Add(time.Hour)
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, TTL: 60, ExpiresAt: &expiresAt})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
 			req = mux.SetURLVars(req, map[string]string{idKey: id})
 			rec := httptest.NewRecorder()
 
 			apiInstance.UpsertRedirect(rec, req)
 
 			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrTTLAndExpiresAt.Error())
 		})
-	})
-}
 
-func TestGetRedirectsSuccessWithValidParams(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the count and cursor values are set to valid values", func() {
-			countValue := "3"
-			cursorValue := "1"
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue+"&cursor="+cursorValue, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
+		Convey("When the ttl is negative", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, TTL: -5})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
-			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			apiInstance.UpsertRedirect(rec, req)
 
-			Convey("Then the response status code should be 200", func() {
-				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidTTL.Error())
+		})
 
-				var response models.Redirects
-				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
-				So(err, ShouldBeNil)
+		Convey("When the expiry time is in the past", func() {
+			expiresAt := time.Now().UTC().Add(-time.Minute)
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, ExpiresAt: &expiresAt})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-				respRedirectList := response.RedirectList
-				respItem1 := respRedirectList[0]
-				respItem1From := respItem1.From
-				expectedID := encodeBase64(respItem1From)
+			apiInstance.UpsertRedirect(rec, req)
 
-				So(response.Count, ShouldEqual, 3)
-				So(len(respRedirectList), ShouldEqual, 3)
-				So(respItem1From, ShouldNotBeEmpty)
-				So(respItem1.To, ShouldNotBeEmpty)
-				So(respItem1.ID, ShouldEqual, expectedID)
-				So(respItem1.Links.Self.ID, ShouldEqual, expectedID)
-				SkipSo(respItem1.Links.Self.Href, ShouldEqual, getRedirectBaseURL+expectedID) // TODO change this back to 'So' when the URL rewriting functionality is fixed
-				So(response.Cursor, ShouldEqual, "1")
-				So(response.NextCursor, ShouldEqual, "0")
-				So(response.TotalCount, ShouldEqual, 12)
-			})
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrExpiresAtInPast.Error())
 		})
-	})
-}
 
-func TestGetRedirectsCountNotAnInteger(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the count value given is not an integer", func() {
-			countValue := notANumber
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
-			mockStore := &storetest.StorerMock{}
-			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
-			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+		Convey("When request is a valid prefix redirect", func() {
+			from := "/economy/inflationandpriceindices/*"
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: "/economy/prices/*"})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			Convey("Then the response status code should be 400", func() {
-				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
-			})
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			So(mockStore.SetValueIfAbsentCalls()[0].Key, ShouldEqual, from)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.Type, ShouldEqual, models.RedirectTypePrefix)
 		})
-	})
-}
 
-func TestGetRedirectsCountNegative(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the count value given is negative", func() {
-			countValue := "-12"
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
-			mockStore := &storetest.StorerMock{}
-			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
-			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+		Convey("When a prefix redirect has a 'from' path without a wildcard", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, Type: models.RedirectTypePrefix})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			Convey("Then the response status code should be 400", func() {
-				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
-			})
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidPrefixRedirect.Error())
 		})
-	})
-}
 
-func TestGetRedirectsCursorNotAnInteger(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the cursor value given is not an integer", func() {
-			cursorValue := notANumber
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?cursor="+cursorValue, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
-			mockStore := &storetest.StorerMock{}
-			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
-			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+		Convey("When an exact redirect contains a wildcard", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: "/bar/*"})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			Convey("Then the response status code should be 400", func() {
-				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
-			})
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrUnexpectedWildcard.Error())
 		})
-	})
-}
 
-func TestGetRedirectsCursorNegative(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the cursor value given is negative", func() {
-			cursorValue := "-7"
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?cursor="+cursorValue, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
-			mockStore := &storetest.StorerMock{}
-			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
-			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+		Convey("When the redirect type is unknown", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, Type: "fuzzy"})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
 
-			Convey("Then the response status code should be 400", func() {
-				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
-			})
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidRedirectType.Error())
 		})
-	})
-}
 
-func TestGetRedirectsServerError(t *testing.T) {
-	Convey("Given a GET /redirects request", t, func() {
-		Convey("When the redirects server has an internal error", func() {
-			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL, http.NoBody)
-			responseRecorder := httptest.NewRecorder()
-			mockStore := &storetest.StorerMock{
+		Convey("When request is a valid host scoped redirect", func() {
+			key := "//cy.ons.gov.uk" + testFromURL
+			id := base64.URLEncoding.EncodeToString([]byte(key))
+			body, _ := json.Marshal(models.Redirect{Host: "cy.ons.gov.uk", From: testFromURL, To: testToURL})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			So(mockStore.GetValueCalls()[0].Key, ShouldEqual, key)
+			So(mockStore.SetValueIfAbsentCalls()[0].Key, ShouldEqual, key)
+		})
+
+		Convey("When the id of a host scoped redirect does not include the host", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{Host: "cy.ons.gov.uk", From: testFromURL, To: testToURL})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrIDFromMismatch.Error())
+		})
+
+		Convey("When the host is not a valid hostname", func() {
+			key := "//CY.ONS.GOV.UK" + testFromURL
+			id := base64.URLEncoding.EncodeToString([]byte(key))
+			body, _ := json.Marshal(models.Redirect{Host: "CY.ONS.GOV.UK", From: testFromURL, To: testToURL})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidHost.Error())
+		})
+
+		Convey("When request is a valid regex redirect", func() {
+			from := `^/datasets/(\w+)/editions/(\d{4})$`
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: `/datasets/$1/editions/$2/latest`})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			So(mockStore.SetValueIfAbsentCalls()[0].Key, ShouldEqual, from)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.Type, ShouldEqual, models.RedirectTypeRegex)
+		})
+
+		Convey("When a regex redirect pattern is not anchored to the start of the path", func() {
+			from := `/datasets/(\w+)$`
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: `/datasets/$1`, Type: models.RedirectTypeRegex})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidRegexRedirect.Error())
+		})
+
+		Convey("When a regex redirect pattern is invalid", func() {
+			from := `^/datasets/(\w+$`
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: `/datasets/$1`})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidRegexPattern.Error())
+			So(rec.Body.String(), ShouldContainSubstring, "missing closing )")
+			So(rec.Body.String(), ShouldContainSubstring, `"code":"`+models.ErrorCodeInvalidRegexPattern+`"`)
+		})
+
+		Convey("When a regex redirect pattern is too complex", func() {
+			from := `^/(\w{1,50}-){1,20}$`
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: `/datasets/$1`})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, models.ErrPatternTooComplex.Error())
+		})
+
+		Convey("When a regex redirect target refers to a group that is not captured", func() {
+			from := `^/datasets/(\w+)$`
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			body, _ := json.Marshal(models.Redirect{From: from, To: `/datasets/$2`})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, models.ErrUnknownCaptureGroup.Error())
+		})
+
+		Convey("When request is valid and contains a query policy", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, QueryPolicy: models.QueryPolicyMerge})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.QueryPolicy, ShouldEqual, models.QueryPolicyMerge)
+		})
+
+		Convey("When request does not contain a query policy", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.QueryPolicy, ShouldEqual, models.DefaultQueryPolicy)
+		})
+
+		Convey("When the query policy is not one of the allowed policies", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, QueryPolicy: "keep"})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidQueryPolicy.Error())
+		})
+
+		Convey("When the validity window ends before it starts", func() {
+			validFrom := time.Now().UTC().Add(2 * time.Hour)
+			validUntil := validFrom.Add(-time.Hour)
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, ValidFrom: &validFrom, ValidUntil: &validUntil})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidValidityWindow.Error())
+		})
+
+		Convey("When request is valid and contains a status code", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			redirect := models.Redirect{
+				From:       testFromURL,
+				To:         testToURL,
+				StatusCode: http.StatusFound,
+			}
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+
+			var stored models.Redirect
+			So(json.Unmarshal([]byte(mockStore.SetValueIfAbsentCalls()[0].Value.(string)), &stored), ShouldBeNil)
+			So(stored.StatusCode, ShouldEqual, http.StatusFound)
+		})
+
+		Convey("When the status code is not an allowed redirect status code", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			redirect := models.Redirect{
+				From:       testFromURL,
+				To:         testToURL,
+				StatusCode: http.StatusOK,
+			}
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+			So(rec.Body.String(), ShouldContainSubstring, api.ErrInvalidStatusCode.Error())
+			So(mockStore.SetValueIfAbsentCalls(), ShouldBeEmpty)
+		})
+
+		Convey("When ID is not valid base64", func() {
+			req := httptest.NewRequest(http.MethodPut, "/redirects/!badid", bytes.NewBuffer([]byte(`{}`)))
+			req = mux.SetURLVars(req, map[string]string{idKey: "!badid"})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When base64 decodes but doesn't match body.from", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			redirect := models.Redirect{
+				From: "/mismatch",
+				To:   testToURL,
+			}
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+
+		Convey("When Redis returns an error", func() {
+			mockStore.SetValueIfAbsentFunc = func(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+				return false, errors.New("redis error")
+			}
+
+			from := testFromURL
+			id := base64.URLEncoding.EncodeToString([]byte(from))
+			redirect := models.Redirect{
+				From: from,
+				To:   testToURL,
+			}
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusInternalServerError)
+		})
+
+		Convey("When request body is invalid JSON", func() {
+			id := base64.URLEncoding.EncodeToString([]byte("/foo"))
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer([]byte(`{bad json`)))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+
+			apiInstance.UpsertRedirect(rec, req)
+
+			So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+func TestUpsertRedirectExternalTargets(t *testing.T) {
+	Convey("Given an UpsertRedirect handler with an allow-list of external hosts", t, func() {
+		mockStore := &storetest.StorerMock{
+			GetValueFunc: func(_ context.Context, _ string) (string, error) {
+				return "", disRedis.ErrKeyNotFound
+			},
+			SetValueFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
+				return nil
+			},
+			SetValueIfAbsentFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+				return true, nil
+			},
+			AddToSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			RemoveFromSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			GetSortedSetRangeByLexFunc: func(_ context.Context, _, _, _ string) ([]string, error) {
+				return []string{}, nil
+			},
+		}
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		externalCfg := *cfg
+		externalCfg.ExternalRedirectHosts = []string{"webarchive.nationalarchives.gov.uk", "*.nhs.uk"}
+		externalCfg.ExternalRedirectSchemes = []string{"https"}
+		apiInstance := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &externalCfg)
+
+		upsert := func(to string) *httptest.ResponseRecorder {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: to})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+			apiInstance.UpsertRedirect(rec, req)
+			return rec
+		}
+
+		Convey("When the target is on an allowed external host", func() {
+			rec := upsert("https://webarchive.nationalarchives.gov.uk/ukgwa/20160105160709/http://www.ons.gov.uk/ons/index.html")
+
+			Convey("Then the redirect is created", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+				So(mockStore.SetValueIfAbsentCalls(), ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the target is on a subdomain of an allowed wildcard host", func() {
+			rec := upsert("https://www.nhs.uk/conditions/coronavirus-covid-19/")
+
+			Convey("Then the redirect is created", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			})
+		})
+
+		Convey("When the target is on a host that is not allowed", func() {
+			for _, to := range []string{
+				"https://example.com/page",
+				"https://nhs.uk.example.com/page",
+				"https://webarchive.nationalarchives.gov.uk@example.com/page",
+				"http://webarchive.nationalarchives.gov.uk/page",
+				"javascript://webarchive.nationalarchives.gov.uk/%0Aalert(1)",
+				"https://example.com\\@webarchive.nationalarchives.gov.uk/",
+			} {
+				rec := upsert(to)
+
+				Convey("Then the redirect to "+to+" is rejected", func() {
+					So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+					So(rec.Body.String(), ShouldContainSubstring, api.ErrExternalHostNotAllowed.Error())
+				})
+			}
+		})
+
+		Convey("When the target is protocol relative", func() {
+			for _, to := range []string{"//webarchive.nationalarchives.gov.uk/page", "/\\example.com"} {
+				rec := upsert(to)
+
+				Convey("Then the redirect to "+to+" is rejected", func() {
+					So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+					So(rec.Body.String(), ShouldContainSubstring, api.ErrFromToNotRelative.Error())
+				})
+			}
+		})
+	})
+
+	Convey("Given an UpsertRedirect handler with the default config", t, func() {
+		apiInstance := GetRedirectAPIWithMocks(store.Datastore{Backend: &storetest.StorerMock{}})
+
+		Convey("When the target is an absolute URL", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(testFromURL))
+			body, _ := json.Marshal(models.Redirect{From: testFromURL, To: "https://webarchive.nationalarchives.gov.uk/page"})
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+			apiInstance.UpsertRedirect(rec, req)
+
+			Convey("Then the redirect is rejected as no external hosts are allowed", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrExternalHostNotAllowed.Error())
+			})
+		})
+	})
+}
+
+func TestUpsertRedirectChains(t *testing.T) {
+	Convey("Given a store containing chains of redirects", t, func() {
+		values := map[string]string{
+			"/b":           "/a",
+			"/c":           "/d",
+			"/d":           "/e?edition=2024",
+			"/e":           "/f",
+			"/x":           "/y",
+			"/y":           "/x",
+			"/economy/*":   `{"to":"/business/*","type":"prefix"}`,
+			"//cy.host/f":  "/g",
+			"//cy.host/g2": "/g",
+		}
+		mockStore := &storetest.StorerMock{
+			GetValueFunc: func(_ context.Context, key string) (string, error) {
+				value, ok := values[key]
+				if !ok {
+					return "", disRedis.ErrKeyNotFound
+				}
+				return value, nil
+			},
+			SetValueFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) error {
+				return nil
+			},
+			SetValueIfAbsentFunc: func(_ context.Context, _ string, _ interface{}, _ time.Duration) (bool, error) {
+				return true, nil
+			},
+			AddToSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			RemoveFromSortedSetFunc: func(_ context.Context, _ string, _ ...string) error {
+				return nil
+			},
+			GetSortedSetRangeByLexFunc: func(_ context.Context, _, _, _ string) ([]string, error) {
+				return []string{}, nil
+			},
+		}
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+
+		upsert := func(apiInstance *api.RedirectAPI, redirect models.Redirect) *httptest.ResponseRecorder {
+			id := base64.URLEncoding.EncodeToString([]byte(redirect.Key()))
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, "/redirects/"+id, bytes.NewBuffer(body))
+			req = mux.SetURLVars(req, map[string]string{idKey: id})
+			rec := httptest.NewRecorder()
+			apiInstance.UpsertRedirect(rec, req)
+			return rec
+		}
+
+		Convey("When the redirect would lead back to its own path", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{From: "/a", To: "/b"})
+
+			Convey("Then it is rejected as a loop", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+				So(mockStore.SetValueIfAbsentCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect would lead into an existing loop", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{From: "/w", To: "/x"})
+
+			Convey("Then it is rejected as a loop", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+			})
+		})
+
+		Convey("When the redirect creates a chain within the maximum depth", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{From: "/h", To: "/d"})
+
+			Convey("Then it is created without a warning", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+				So(rec.Header().Get("Warning"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect creates a chain longer than the maximum depth and the policy is to warn", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{From: "/h", To: "/c"})
+
+			Convey("Then it is created with a warning", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+				So(rec.Header().Get("Warning"), ShouldContainSubstring, "longer than the maximum depth")
+				So(mockStore.SetValueIfAbsentCalls(), ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the redirect creates a chain longer than the maximum depth and the policy is to reject", func() {
+			rejectCfg := *cfg
+			rejectCfg.RedirectChainPolicy = config.RedirectChainPolicyReject
+			apiInstance := api.Setup(context.Background(), mux.NewRouter(), &store.Datastore{Backend: mockStore}, newAuthMiddlwareMock(), &rejectCfg)
+
+			rec := upsert(apiInstance, models.Redirect{From: "/h", To: "/c"})
+
+			Convey("Then it is rejected", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectChainTooLong.Error())
+				So(mockStore.SetValueIfAbsentCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a host scoped redirect leads into redirects scoped to the same host", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{Host: "cy.host", From: "/g", To: "/f"})
+
+			Convey("Then the chain is followed through the host scoped redirects", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+			})
+		})
+
+		Convey("When the redirect leads to a prefix redirect", func() {
+			rec := upsert(GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore}), models.Redirect{From: "/business/*", To: "/economy/*"})
+
+			Convey("Then the chain is not followed", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+				So(rec.Header().Get("Warning"), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestUpsertRedirectRoundTrip(t *testing.T) {
+	Convey("Given a redirect that expires", t, func() {
+		values := map[string]string{}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+		id := encodeBase64(testFromURL)
+
+		put := func(body []byte) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		body, _ := json.Marshal(models.Redirect{From: testFromURL, To: testToURL, TTL: 3600})
+		So(put(body).Code, ShouldEqual, http.StatusCreated)
+
+		Convey("When it is read and written back unchanged", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectBaseURL+id, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+			var read models.Redirect
+			So(json.Unmarshal(responseRecorder.Body.Bytes(), &read), ShouldBeNil)
+			So(read.TTL, ShouldBeGreaterThan, 0)
+			So(read.ExpiresAt, ShouldNotBeNil)
+
+			responseRecorder = put(responseRecorder.Body.Bytes())
+
+			Convey("Then it is accepted and keeps its expiry time", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var stored models.Redirect
+				So(json.Unmarshal([]byte(values[testFromURL]), &stored), ShouldBeNil)
+				So(stored.ExpiresAt.Equal(*read.ExpiresAt), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestUpsertRedirectChainsThroughPatterns(t *testing.T) {
+	Convey("Given a store containing prefix and regex redirects", t, func() {
+		values := map[string]string{
+			"/old/*":                  `{"to":"/new/*","type":"prefix"}`,
+			`^/datasets/(\w+)$`:       `{"to":"/data/$1","type":"regex"}`,
+			"/new/a":                  "/n2",
+			"/n2":                     "/n3",
+			"//cy.ons.gov.uk/other/*": `{"to":"/old/*","type":"prefix"}`,
+		}
+		dataStore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+		_, err := dataStore.RebuildIndexes(context.Background())
+		So(err, ShouldBeNil)
+		redirectAPI := GetRedirectAPIWithMocks(dataStore)
+
+		upsert := func(redirect models.Redirect) *httptest.ResponseRecorder {
+			id := base64.URLEncoding.EncodeToString([]byte(redirect.Key()))
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			rec := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		Convey("When the redirect would lead back to its own path through a prefix redirect", func() {
+			rec := upsert(models.Redirect{From: "/new/page", To: "/old/page"})
+
+			Convey("Then it is rejected as a loop", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+				So(values, ShouldNotContainKey, "/new/page")
+			})
+		})
+
+		Convey("When the redirect would lead back to its own path through a regex redirect", func() {
+			rec := upsert(models.Redirect{From: "/data/cpih", To: "/datasets/cpih"})
+
+			Convey("Then it is rejected as a loop", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+			})
+		})
+
+		Convey("When a host scoped redirect leads back to its own path through global and host scoped prefix redirects", func() {
+			rec := upsert(models.Redirect{Host: "cy.ons.gov.uk", From: "/new/b", To: "/other/b"})
+
+			Convey("Then it is rejected as a loop", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectLoop.Error())
+			})
+		})
+
+		Convey("When the redirect creates a chain through a prefix redirect longer than the maximum depth", func() {
+			rec := upsert(models.Redirect{From: "/start", To: "/old/a"})
+
+			Convey("Then every hop is counted and it is created with a warning", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+				So(rec.Header().Get("Warning"), ShouldContainSubstring, "longer than the maximum depth")
+			})
+		})
+	})
+}
+
+func TestUpsertRedirectAppendsToChain(t *testing.T) {
+	Convey("Given a store containing the chain /a -> /b -> /c -> /d", t, func() {
+		values := map[string]string{
+			"/a":                 "/b",
+			"/b":                 "/c",
+			"/c":                 "/d",
+			"//cy.ons.gov.uk/x":  "/y",
+			"//cy.ons.gov.uk/y":  "/z",
+			"//cy.ons.gov.uk/z":  "/d",
+			"/unrelated":         "/elsewhere",
+			"/unrelated/further": "/unrelated",
+		}
+		dataStore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+		_, err := dataStore.RebuildIndexes(context.Background())
+		So(err, ShouldBeNil)
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		rejectCfg := *cfg
+		rejectCfg.RedirectChainPolicy = config.RedirectChainPolicyReject
+		redirectAPI := api.Setup(context.Background(), mux.NewRouter(), &dataStore, newAuthMiddlwareMock(), &rejectCfg)
+
+		upsert := func(redirect models.Redirect) *httptest.ResponseRecorder {
+			id := base64.URLEncoding.EncodeToString([]byte(redirect.Key()))
+			body, _ := json.Marshal(redirect)
+			req := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			rec := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(rec, req)
+			return rec
+		}
+
+		Convey("When /d -> /e is added to the end of the chain", func() {
+			rec := upsert(models.Redirect{From: "/d", To: "/e"})
+
+			Convey("Then the hops leading to it are counted and it is rejected", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectChainTooLong.Error())
+				So(values, ShouldNotContainKey, "/d")
+			})
+		})
+
+		Convey("When a redirect is added to the end of a shorter chain", func() {
+			rec := upsert(models.Redirect{From: "/elsewhere", To: "/final"})
+
+			Convey("Then it is created", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			})
+		})
+
+		Convey("When a host scoped redirect is added to the end of a chain scoped to the same host", func() {
+			rec := upsert(models.Redirect{Host: "cy.ons.gov.uk", From: "/d", To: "/e"})
+
+			Convey("Then the hops leading to it are counted and it is rejected", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusBadRequest)
+				So(rec.Body.String(), ShouldContainSubstring, api.ErrRedirectChainTooLong.Error())
+			})
+		})
+
+		Convey("When a host scoped redirect is added to the end of a chain scoped to another host", func() {
+			rec := upsert(models.Redirect{Host: "en.ons.gov.uk", From: "/d", To: "/e"})
+
+			Convey("Then the redirects scoped to the other host are not counted and it is created", func() {
+				So(rec.Result().StatusCode, ShouldEqual, http.StatusCreated)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsIncludesActivationStatus(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the store contains active, scheduled and ended redirects", func() {
+			now := time.Now().UTC()
+			future := now.Add(time.Hour).Format(time.RFC3339)
+			past := now.Add(-time.Hour).Format(time.RFC3339)
+
+			mockStore := &storetest.StorerMock{
+				GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
+					return map[string]string{
+						economyBulletin1: financeBulletin1,
+						economyBulletin2: `{"to":"` + financeBulletin2 + `","valid_from":"` + future + `"}`,
+						economyBulletin3: `{"to":"` + financeBulletin3 + `","valid_until":"` + past + `"}`,
+					}, 0, nil
+				},
+				GetTotalKeysFunc: func(_ context.Context) (int64, error) {
+					return 3, nil
+				},
+				CountExistingKeysFunc: func(_ context.Context, _ ...string) (int64, error) {
+					return 0, nil
+				},
+			}
+
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then every redirect is returned with its activation status", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirects
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+
+				statuses := map[string]string{}
+				for _, redirect := range response.RedirectList {
+					statuses[redirect.From] = redirect.Status
+				}
+				So(statuses, ShouldResemble, map[string]string{
+					economyBulletin1: models.RedirectStatusActive,
+					economyBulletin2: models.RedirectStatusScheduled,
+					economyBulletin3: models.RedirectStatusEnded,
+				})
+			})
+		})
+	})
+}
+
+func TestGetRedirectsFilteredByHost(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		mockStore := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
+				return map[string]string{
+					"//cy.ons.gov.uk" + economyBulletin1: financeBulletin1,
+				}, 0, nil
+			},
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		Convey("When the redirects are filtered by host", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?host=cy.ons.gov.uk", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then only the redirects scoped to the host are requested from the store", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				calls := mockStore.GetKeyValuePairsCalls()
+				So(calls, ShouldHaveLength, 2)
+				So(calls[0].MatchPattern, ShouldEqual, "//cy.ons.gov.uk[/^]*")
+				So(calls[1].MatchPattern, ShouldEqual, "//cy.ons.gov.uk[/^]*")
+			})
+
+			Convey("And the redirects include their host and an id made from their host and 'from' path", func() {
+				var response models.Redirects
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+
+				So(response.RedirectList, ShouldHaveLength, 1)
+				So(response.RedirectList[0].Host, ShouldEqual, "cy.ons.gov.uk")
+				So(response.RedirectList[0].From, ShouldEqual, economyBulletin1)
+				So(response.RedirectList[0].ID, ShouldEqual, encodeBase64("//cy.ons.gov.uk"+economyBulletin1))
+				So(response.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the host filter is not a valid hostname", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?host=CY.ONS.GOV.UK/*", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidHost.Error())
+				So(mockStore.GetKeyValuePairsCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsFilteredByPath(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		mockStore := storetest.NewMapStorer(map[string]string{
+			economyBulletin1:                     financeBulletin1,
+			economyBulletin2:                     financeBulletin2,
+			financeBulletin3:                     economyBulletin3,
+			"//cy.ons.gov.uk" + economyBulletin3: financeBulletin3,
+		})
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		get := func(query string) (*httptest.ResponseRecorder, models.Redirects) {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?"+query, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			var response models.Redirects
+			if responseRecorder.Code == http.StatusOK {
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+			}
+			return responseRecorder, response
+		}
+
+		Convey("When the redirects are filtered by prefix", func() {
+			responseRecorder, response := get("prefix=/economy/")
+
+			Convey("Then only the redirects from paths starting with the prefix are returned and counted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, "*/economy/*")
+				So(response.RedirectList, ShouldHaveLength, 3)
+				So(response.TotalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the redirects are filtered by prefix and host", func() {
+			responseRecorder, response := get("prefix=/economy/&host=cy.ons.gov.uk")
+
+			Convey("Then only the redirects scoped to the host are returned and counted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, "//cy.ons.gov.uk/economy/*")
+				So(response.RedirectList, ShouldHaveLength, 1)
+				So(response.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the redirects are filtered by the text their paths contain", func() {
+			responseRecorder, response := get("contains=bulletin2")
+
+			Convey("Then only the redirects from paths containing the text are returned and counted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(response.RedirectList, ShouldHaveLength, 1)
+				So(response.RedirectList[0].From, ShouldEqual, economyBulletin2)
+				So(response.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the prefix does not start with a '/'", func() {
+			responseRecorder, _ := get("prefix=economy")
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidPrefix.Error())
+				So(mockStore.GetKeyValuePairsCalls(), ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsTo(t *testing.T) {
+	Convey("Given redirects to the same target", t, func() {
+		values := map[string]string{
+			economyBulletin1:                     financeBulletin1,
+			"//cy.ons.gov.uk" + economyBulletin2: financeBulletin1 + "?lang=cy",
+			economyBulletin3:                     financeBulletin3,
+		}
+		mockStore := storetest.NewMapStorer(values)
+		dataStore := store.Datastore{Backend: mockStore}
+		_, err := dataStore.RebuildIndexes(context.Background())
+		So(err, ShouldBeNil)
+		scans := len(mockStore.GetKeyValuePairsCalls())
+		redirectAPI := GetRedirectAPIWithMocks(dataStore)
+
+		Convey("When the redirects to the target are requested", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?to="+financeBulletin1, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then every redirect landing on the target is returned from the reverse index", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.GetKeyValuePairsCalls(), ShouldHaveLength, scans)
+
+				var response models.Redirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.RedirectList, ShouldHaveLength, 2)
+				So(response.RedirectList[0].Key(), ShouldEqual, "//cy.ons.gov.uk"+economyBulletin2)
+				So(response.RedirectList[0].ID, ShouldEqual, encodeBase64("//cy.ons.gov.uk"+economyBulletin2))
+				So(response.RedirectList[1].Key(), ShouldEqual, economyBulletin1)
+				So(response.Count, ShouldEqual, 2)
+				So(response.TotalCount, ShouldEqual, 2)
+				So(response.NextCursor, ShouldEqual, "0")
+			})
+		})
+
+		Convey("When the redirects to the target are requested for a host", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?to="+financeBulletin1+"&host=cy.ons.gov.uk", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then only the redirects scoped to the host are returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.RedirectList, ShouldHaveLength, 1)
+				So(response.RedirectList[0].Host, ShouldEqual, "cy.ons.gov.uk")
+				So(response.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When no redirects land on the target", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?to=/nowhere", http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then an empty list is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirects
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.RedirectList, ShouldBeEmpty)
+				So(response.TotalCount, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a redirect is moved to another target", func() {
+			id := base64.URLEncoding.EncodeToString([]byte(economyBulletin1))
+			body, _ := json.Marshal(models.Redirect{From: economyBulletin1, To: financeBulletin3})
+			request := httptest.NewRequest(http.MethodPut, getRedirectBaseURL+id, bytes.NewBuffer(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the reverse index follows it", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				landing, err := dataStore.GetRedirectsTo(context.Background(), financeBulletin1)
+				So(err, ShouldBeNil)
+				So(landing, ShouldHaveLength, 1)
+				So(landing[0].Key(), ShouldEqual, "//cy.ons.gov.uk"+economyBulletin2)
+
+				landing, err = dataStore.GetRedirectsTo(context.Background(), financeBulletin3)
+				So(err, ShouldBeNil)
+				So(landing, ShouldHaveLength, 2)
+				So(landing[0].Key(), ShouldEqual, economyBulletin1)
+				So(landing[1].Key(), ShouldEqual, economyBulletin3)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsSuccessWithValidParams(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the count and cursor values are set to valid values", func() {
+			countValue := "3"
+			cursorValue := "1"
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue+"&cursor="+cursorValue, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 200", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirects
+				err := json.Unmarshal(responseRecorder.Body.Bytes(), &response)
+				So(err, ShouldBeNil)
+
+				respRedirectList := response.RedirectList
+				respItem1 := respRedirectList[0]
+				respItem1From := respItem1.From
+				expectedID := encodeBase64(respItem1From)
+
+				So(response.Count, ShouldEqual, 3)
+				So(len(respRedirectList), ShouldEqual, 3)
+				So(respItem1From, ShouldNotBeEmpty)
+				So(respItem1.To, ShouldNotBeEmpty)
+				So(respItem1.ID, ShouldEqual, expectedID)
+				So(respItem1.Links.Self.ID, ShouldEqual, expectedID)
+				SkipSo(respItem1.Links.Self.Href, ShouldEqual, getRedirectBaseURL+expectedID) // TODO change this back to 'So' when the URL rewriting functionality is fixed
+				So(response.Cursor, ShouldEqual, "1")
+				So(response.NextCursor, ShouldEqual, "0")
+				So(response.TotalCount, ShouldEqual, 3)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsCountNotAnInteger(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the count value given is not an integer", func() {
+			countValue := notANumber
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			mockStore := &storetest.StorerMock{}
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsCountNegative(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the count value given is negative", func() {
+			countValue := "-12"
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?count="+countValue, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			mockStore := &storetest.StorerMock{}
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsCursorNotAnInteger(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the cursor value given is not an integer", func() {
+			cursorValue := notANumber
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?cursor="+cursorValue, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			mockStore := &storetest.StorerMock{}
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsCursorNegative(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the cursor value given is negative", func() {
+			cursorValue := "-7"
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL+"?cursor="+cursorValue, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			mockStore := &storetest.StorerMock{}
+			redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
+
+func TestGetRedirectsServerError(t *testing.T) {
+	Convey("Given a GET /redirects request", t, func() {
+		Convey("When the redirects server has an internal error", func() {
+			request := httptest.NewRequest(http.MethodGet, getRedirectsBaseURL, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			mockStore := &storetest.StorerMock{
 				GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
 					return nil, 0, api.ErrInternal
 				},
@@ -525,13 +1720,10 @@ func TestDeleteRedirect(t *testing.T) {
 		base64ID := base64.URLEncoding.EncodeToString([]byte("/test-path"))
 
 		Convey("When the redirect exists and is deleted successfully", func() {
-			mockStore.GetValueFunc = func(_ context.Context, key string) (string, error) {
-				if key == "/test-path" {
-					return "/target", nil
-				}
-				return "", nil
+			mockStore.DeleteValueReturningPreviousFunc = func(_ context.Context, _ string) (string, error) {
+				return "/target", nil
 			}
-			mockStore.DeleteValueFunc = func(_ context.Context, _ string) error {
+			mockStore.RemoveFromSortedSetFunc = func(_ context.Context, _ string, _ ...string) error {
 				return nil
 			}
 
@@ -540,10 +1732,14 @@ func TestDeleteRedirect(t *testing.T) {
 			router.ServeHTTP(rr, req)
 
 			So(rr.Code, ShouldEqual, http.StatusNoContent)
+			So(mockStore.DeleteValueReturningPreviousCalls(), ShouldHaveLength, 1)
+			So(mockStore.DeleteValueReturningPreviousCalls()[0].Key, ShouldEqual, "/test-path")
+			So(mockStore.RemoveFromSortedSetCalls(), ShouldHaveLength, 1)
+			So(mockStore.RemoveFromSortedSetCalls()[0].Members, ShouldResemble, []string{"/target\x00/test-path"})
 		})
 
 		Convey("When the redirect does not exist", func() {
-			mockStore.GetValueFunc = func(_ context.Context, _ string) (string, error) {
+			mockStore.DeleteValueReturningPreviousFunc = func(_ context.Context, _ string) (string, error) {
 				return "", disRedis.ErrKeyNotFound
 			}
 
@@ -555,8 +1751,8 @@ func TestDeleteRedirect(t *testing.T) {
 			So(rr.Body.String(), ShouldContainSubstring, "not found")
 		})
 
-		Convey("When an internal error occurs during existence check", func() {
-			mockStore.GetValueFunc = func(_ context.Context, _ string) (string, error) {
+		Convey("When an internal error occurs while deleting", func() {
+			mockStore.DeleteValueReturningPreviousFunc = func(_ context.Context, _ string) (string, error) {
 				return "", errors.New("connection failed")
 			}
 
@@ -569,7 +1765,7 @@ func TestDeleteRedirect(t *testing.T) {
 		})
 
 		Convey("When the base64 id is invalid", func() {
-			req := httptest.NewRequest(http.MethodDelete, "/redirects/invalid_base64", http.NoBody)
+			req := httptest.NewRequest(http.MethodDelete, "/redirects/invalid*base64", http.NoBody)
 			rr := httptest.NewRecorder()
 			router.ServeHTTP(rr, req)
 
@@ -578,3 +1774,285 @@ func TestDeleteRedirect(t *testing.T) {
 		})
 	})
 }
+
+func TestConcurrentChanges(t *testing.T) {
+	Convey("Given a stored redirect that another request changes at the same time", t, func() {
+		values := map[string]string{
+			"/economy": `{"to":"/business","status_code":302}`,
+		}
+		mockStore := storetest.NewMapStorer(values)
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: mockStore})
+
+		// changeBeforeWrites changes the stored redirect to the given target before each of the next writes of it,
+		// as if another request had changed it after it was read
+		changeBeforeWrites := func(writes int, to string, statusCode int) {
+			compareAndSwap := mockStore.CompareAndSwapValueFunc
+			mockStore.CompareAndSwapValueFunc = func(ctx context.Context, key, expected string, newValue interface{}, expiration time.Duration) error {
+				if write := len(mockStore.CompareAndSwapValueCalls()); write <= writes {
+					values[key] = fmt.Sprintf(`{"to":%q,"status_code":%d,"updated_by":"request-%d"}`, to, statusCode, write)
+				}
+				return compareAndSwap(ctx, key, expected, newValue, expiration)
+			}
+		}
+
+		sendRequest := func(method, key string, headers map[string]string, body string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(method, getRedirectBaseURL+encodeBase64(key), bytes.NewBufferString(body))
+			for name, value := range headers {
+				request.Header.Set(name, value)
+			}
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		Convey("When the redirect is replaced and it is changed once while being saved", func() {
+			changeBeforeWrites(1, "/people", http.StatusFound)
+			responseRecorder := sendRequest(http.MethodPut, "/economy", nil, `{"from": "/economy", "to": "/census"}`)
+
+			Convey("Then the redirect is saved on the second attempt", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 2)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/census")
+			})
+		})
+
+		Convey("When the redirect is replaced and it keeps being changed while being saved", func() {
+			changeBeforeWrites(3, "/people", http.StatusFound)
+			responseRecorder := sendRequest(http.MethodPut, "/economy", nil, `{"from": "/economy", "to": "/census"}`)
+
+			Convey("Then a conflict is returned and the other change is kept", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusConflict)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrWriteConflict.Error())
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 3)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/people")
+			})
+		})
+
+		Convey("When a redirect is created and another request creates it first", func() {
+			setValueIfAbsent := mockStore.SetValueIfAbsentFunc
+			mockStore.SetValueIfAbsentFunc = func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				values[key] = `{"to":"/people"}`
+				return setValueIfAbsent(ctx, key, value, expiration)
+			}
+			responseRecorder := sendRequest(http.MethodPut, "/census", nil, `{"from": "/census", "to": "/business"}`)
+
+			Convey("Then the redirect created by the other request is replaced", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.SetValueIfAbsentCalls(), ShouldHaveLength, 1)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 1)
+				So(storedTarget(values, "/census"), ShouldEqual, "/business")
+			})
+		})
+
+		Convey("When a redirect is only to be created and another request creates it first", func() {
+			setValueIfAbsent := mockStore.SetValueIfAbsentFunc
+			mockStore.SetValueIfAbsentFunc = func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				values[key] = `{"to":"/people"}`
+				return setValueIfAbsent(ctx, key, value, expiration)
+			}
+			responseRecorder := sendRequest(http.MethodPut, "/census", map[string]string{api.HeaderIfNoneMatch: "*"}, `{"from": "/census", "to": "/business"}`)
+
+			Convey("Then the precondition fails and the redirect created by the other request is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(responseRecorder.Header().Get(api.HeaderETag), ShouldNotBeEmpty)
+
+				var errorResponse models.ErrorResponse
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &errorResponse), ShouldBeNil)
+				So(errorResponse.Code, ShouldEqual, models.ErrorCodeRedirectExists)
+				So(errorResponse.Existing, ShouldNotBeNil)
+				So(errorResponse.Existing.To, ShouldEqual, "/people")
+				So(mockStore.CompareAndSwapValueCalls(), ShouldBeEmpty)
+				So(storedTarget(values, "/census"), ShouldEqual, "/people")
+			})
+		})
+
+		Convey("When the redirect is patched and it is changed while being saved", func() {
+			changeBeforeWrites(1, "/people", http.StatusTemporaryRedirect)
+			responseRecorder := sendRequest(http.MethodPatch, "/economy", nil, `{"to": "/census"}`)
+
+			Convey("Then the patch is applied again to the changed redirect", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+				So(mockStore.CompareAndSwapValueCalls(), ShouldHaveLength, 2)
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.To, ShouldEqual, "/census")
+				So(response.StatusCode, ShouldEqual, http.StatusTemporaryRedirect)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/census")
+			})
+		})
+
+		Convey("When the redirect is deleted with an If-Match header and it is changed before it is deleted", func() {
+			etag := sendRequest(http.MethodGet, "/economy", nil, "").Header().Get(api.HeaderETag)
+			compareAndDelete := mockStore.CompareAndDeleteValueFunc
+			mockStore.CompareAndDeleteValueFunc = func(ctx context.Context, key, expected string) error {
+				values[key] = `{"to":"/people"}`
+				return compareAndDelete(ctx, key, expected)
+			}
+			responseRecorder := sendRequest(http.MethodDelete, "/economy", map[string]string{api.HeaderIfMatch: etag}, "")
+
+			Convey("Then the precondition fails and the changed redirect is kept", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusPreconditionFailed)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/people")
+			})
+		})
+
+		Convey("When the redirect is deleted and another request deletes it first", func() {
+			deleteValueReturningPrevious := mockStore.DeleteValueReturningPreviousFunc
+			mockStore.DeleteValueReturningPreviousFunc = func(ctx context.Context, key string) (string, error) {
+				delete(values, key)
+				return deleteValueReturningPrevious(ctx, key)
+			}
+			responseRecorder := sendRequest(http.MethodDelete, "/economy", nil, "")
+
+			Convey("Then the redirect is not found", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestRedirectsAddressedByPath(t *testing.T) {
+	Convey("Given redirects from the same path that are global and scoped to a host", t, func() {
+		values := map[string]string{
+			"/economy":                `{"to":"/business"}`,
+			"//cy.ons.gov.uk/economy": `{"to":"/busnes"}`,
+			"/~~~":                    `{"to":"/tilde"}`,
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		sendRequest := func(method, url string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(method, url, http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		Convey("When the global redirect is requested by its path", func() {
+			responseRecorder := sendRequest(http.MethodGet, getRedirectsBaseURL+"?path=/economy")
+
+			Convey("Then the global redirect is returned with its id", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.To, ShouldEqual, "/business")
+				So(response.ID, ShouldEqual, encodeBase64("/economy"))
+				So(response.Links.Self.Href, ShouldEqual, selfBaseURL+encodeBase64("/economy"))
+			})
+		})
+
+		Convey("When the host scoped redirect is requested by its host and path", func() {
+			responseRecorder := sendRequest(http.MethodGet, getRedirectsBaseURL+"?path=/economy&host=cy.ons.gov.uk")
+
+			Convey("Then the host scoped redirect is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Host, ShouldEqual, "cy.ons.gov.uk")
+				So(response.To, ShouldEqual, "/busnes")
+			})
+		})
+
+		Convey("When a redirect is requested by a path that is not redirected", func() {
+			responseRecorder := sendRequest(http.MethodGet, getRedirectsBaseURL+"?path=/missing")
+
+			Convey("Then the response status code should be 404", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When a redirect is requested by a path that is not relative", func() {
+			responseRecorder := sendRequest(http.MethodGet, getRedirectsBaseURL+"?path=economy")
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, models.ErrorCodeInvalidRedirectPath)
+			})
+		})
+
+		Convey("When a redirect is requested by an id without padding", func() {
+			responseRecorder := sendRequest(http.MethodGet, getRedirectBaseURL+"L35-fg")
+
+			Convey("Then the redirect is returned with its padded id", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.To, ShouldEqual, "/tilde")
+				So(response.ID, ShouldEqual, "L35-fg==")
+			})
+		})
+
+		Convey("When the host scoped redirect is deleted by its host and path", func() {
+			responseRecorder := sendRequest(http.MethodDelete, getRedirectsBaseURL+"?path=/economy&host=cy.ons.gov.uk")
+
+			Convey("Then only the host scoped redirect is deleted", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNoContent)
+				So(values, ShouldNotContainKey, "//cy.ons.gov.uk/economy")
+				So(values, ShouldContainKey, "/economy")
+			})
+		})
+
+		Convey("When a redirect is deleted by a path that is not redirected", func() {
+			responseRecorder := sendRequest(http.MethodDelete, getRedirectsBaseURL+"?path=/missing")
+
+			Convey("Then the response status code should be 404", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestCreateRedirect(t *testing.T) {
+	Convey("Given a stored redirect", t, func() {
+		values := map[string]string{
+			"/economy": `{"to":"/business"}`,
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		createRedirect := func(body string) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodPost, getRedirectsBaseURL, bytes.NewBufferString(body))
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		Convey("When a redirect from another path is created", func() {
+			responseRecorder := createRedirect(`{"from": "/census", "to": "/people"}`)
+
+			Convey("Then the created redirect is returned along with its location", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusCreated)
+				So(responseRecorder.Header().Get("Location"), ShouldEqual, selfBaseURL+encodeBase64("/census"))
+				So(storedTarget(values, "/census"), ShouldEqual, "/people")
+
+				var response models.Redirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.ID, ShouldEqual, encodeBase64("/census"))
+				So(response.To, ShouldEqual, "/people")
+				So(response.CreatedAt, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When a redirect from the same path is created", func() {
+			responseRecorder := createRedirect(`{"from": "/economy", "to": "/people"}`)
+
+			Convey("Then a conflict is returned and the stored redirect is kept", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusConflict)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, models.ErrorCodeRedirectExists)
+				So(storedTarget(values, "/economy"), ShouldEqual, "/business")
+			})
+		})
+
+		Convey("When an invalid redirect is created", func() {
+			responseRecorder := createRedirect(`{"from": "census", "to": "/people"}`)
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrFromToNotRelative.Error())
+				So(values, ShouldNotContainKey, "census")
+			})
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/ONSdigital/dp-net/v2/links"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// resolve handles working out where a requested path ends up, following each redirect that applies in turn
+// until it reaches a path that is not redirected or a target on another site
+func (api *RedirectAPI) resolve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requested := r.URL.Query().Get(QueryParameterPath)
+	host := r.URL.Query().Get(QueryParameterHost)
+	logData := log.Data{QueryParameterPath: requested, QueryParameterHost: host}
+
+	if !isValidRelativePath(requested) {
+		log.Info(ctx, "invalid query parameter - path should be a relative path", logData)
+		api.handleError(ctx, w, ErrInvalidPath, http.StatusBadRequest)
+		return
+	}
+
+	if host != "" && !models.IsValidHost(host) {
+		log.Info(ctx, "invalid query parameter - host should be a lowercase hostname", logData)
+		api.handleError(ctx, w, ErrInvalidHost, http.StatusBadRequest)
+		return
+	}
+
+	linkBuilder := links.FromHeadersOrDefault(&r.Header, api.apiURL)
+	resolved, err := api.resolvePath(ctx, linkBuilder, host, requested, time.Now().UTC())
+	if err != nil {
+		switch {
+		case errors.Is(err, disRedis.ErrKeyNotFound):
+			log.Info(ctx, "no redirect applies to the path", logData)
+			api.handleError(ctx, w, ErrNotFound, http.StatusNotFound)
+		case errors.Is(err, ErrResolveLoop), errors.Is(err, ErrTooManyHops):
+			log.Info(ctx, "path could not be resolved", log.Data{QueryParameterPath: requested, QueryParameterHost: host, "reason": err.Error()})
+			api.handleError(ctx, w, err, http.StatusLoopDetected)
+		default:
+			log.Error(ctx, "redis failed on resolving path", err, logData)
+			api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resolvedResponse, err := json.Marshal(resolved)
+	if err != nil {
+		log.Error(ctx, "failed to marshal response", err, logData)
+		api.handleError(ctx, w, ErrInternal, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(resolvedResponse); err != nil {
+		log.Error(ctx, "failed to write response", err, logData)
+	}
+}
+
+// resolvePath follows the active redirects that apply to the requested path on the given host, recording each
+// one as a hop. disRedis.ErrKeyNotFound is returned if no redirect applies to the requested path, ErrResolveLoop
+// if the redirects lead back to a path already visited and ErrTooManyHops if they go on for more than the
+// maximum number of hops.
+func (api *RedirectAPI) resolvePath(ctx context.Context, linkBuilder *links.Builder, host, requested string, now time.Time) (*models.ResolvedRedirect, error) {
+	resolved := &models.ResolvedRedirect{
+		Path: requested,
+		Hops: []models.RedirectHop{},
+	}
+
+	visited := map[string]bool{}
+	statusCodes := []int{}
+	target := requested
+	for isValidRelativePath(target) {
+		path, rawQuery := splitTarget(target)
+		path = api.canonicalPath(path)
+
+		if visited[path] {
+			return nil, ErrResolveLoop
+		}
+		visited[path] = true
+
+		redirect, err := api.RedirectStore.MatchRedirect(ctx, host, path, now)
+		if err != nil {
+			if errors.Is(err, disRedis.ErrKeyNotFound) && len(resolved.Hops) > 0 {
+				break
+			}
+			return nil, err
+		}
+
+		if len(resolved.Hops) == api.resolveMaxHops {
+			return nil, ErrTooManyHops
+		}
+
+		hop := models.RedirectHop{
+			Path:       target,
+			Target:     redirect.ResolveTarget(path, rawQuery),
+			StatusCode: redirect.StatusCode,
+		}
+		if err := setRedirectLinks(linkBuilder, redirect); err != nil {
+			return nil, fmt.Errorf("failed to build redirect link: %w", err)
+		}
+		hop.Links = redirect.Links
+
+		resolved.Hops = append(resolved.Hops, hop)
+		statusCodes = append(statusCodes, redirect.StatusCode)
+		target = hop.Target
+	}
+
+	resolved.Target = target
+	resolved.StatusCode = models.ChainStatusCode(statusCodes)
+
+	return resolved, nil
+}
+
+// splitTarget splits a relative target into its path and raw query string, discarding any fragment
+func splitTarget(target string) (path, rawQuery string) {
+	path, rawQuery, _ = strings.Cut(target, "?")
+	path, _, _ = strings.Cut(path, "#")
+	rawQuery, _, _ = strings.Cut(rawQuery, "#")
+
+	return path, rawQuery
+}
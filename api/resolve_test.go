@@ -0,0 +1,176 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const resolveBaseURL = "http://localhost:29900/v1/resolve"
+
+func TestResolve(t *testing.T) {
+	Convey("Given a store with chains of redirects", t, func() {
+		values := map[string]string{
+			"/a":                "/b",
+			"/b":                `{"to":"/c?edition=2024","status_code":302}`,
+			"/economy/*":        `{"to":"/business/*","type":"prefix","status_code":308}`,
+			"/loop1":            "/loop2",
+			"/loop2":            "/loop1",
+			"/external":         "https://www.nhs.uk/conditions",
+			"//cy.ons.gov.uk/a": "/cy/c",
+		}
+		for i := 0; i <= 10; i++ {
+			values[fmt.Sprintf("/hop%d", i)] = fmt.Sprintf("/hop%d", i+1)
+		}
+		redirectAPI := GetRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)})
+
+		resolve := func(redirectAPI *api.RedirectAPI, query url.Values) *httptest.ResponseRecorder {
+			request := httptest.NewRequest(http.MethodGet, resolveBaseURL+"?"+query.Encode(), http.NoBody)
+			responseRecorder := httptest.NewRecorder()
+			redirectAPI.Router.ServeHTTP(responseRecorder, request)
+			return responseRecorder
+		}
+
+		Convey("When a path at the start of a chain is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/a?lang=en"}})
+
+			Convey("Then the end of the chain and every hop are returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Path, ShouldEqual, "/a?lang=en")
+				So(response.Target, ShouldEqual, "/c?edition=2024&lang=en")
+				So(response.StatusCode, ShouldEqual, http.StatusFound)
+				So(response.Hops, ShouldHaveLength, 2)
+				So(response.Hops[0].Path, ShouldEqual, "/a?lang=en")
+				So(response.Hops[0].Target, ShouldEqual, "/b?lang=en")
+				So(response.Hops[0].StatusCode, ShouldEqual, http.StatusMovedPermanently)
+				So(response.Hops[0].Links.Self.ID, ShouldEqual, encodeBase64("/a"))
+				So(response.Hops[1].Path, ShouldEqual, "/b?lang=en")
+				So(response.Hops[1].Target, ShouldEqual, "/c?edition=2024&lang=en")
+				So(response.Hops[1].StatusCode, ShouldEqual, http.StatusFound)
+			})
+		})
+
+		Convey("When a path matched by a prefix redirect is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/economy/a"}})
+
+			Convey("Then the redirects are followed from the prefix target", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Target, ShouldEqual, "/business/a")
+				So(response.StatusCode, ShouldEqual, http.StatusPermanentRedirect)
+				So(response.Hops, ShouldHaveLength, 1)
+				So(response.Hops[0].Links.Self.ID, ShouldEqual, encodeBase64("/economy/*"))
+			})
+		})
+
+		Convey("When a path redirected to another site is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/external"}})
+
+			Convey("Then the redirects stop at the other site", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Target, ShouldEqual, "https://www.nhs.uk/conditions")
+				So(response.Hops, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a path is resolved on a host", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/a"}, "host": {"cy.ons.gov.uk"}})
+
+			Convey("Then the redirects scoped to the host are used", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Target, ShouldEqual, "/cy/c")
+				So(response.Hops[0].Links.Self.ID, ShouldEqual, encodeBase64("//cy.ons.gov.uk/a"))
+			})
+		})
+
+		Convey("When a variant of a path is resolved with path normalisation enabled", func() {
+			responseRecorder := resolve(getNormalisingRedirectAPIWithMocks(store.Datastore{Backend: storetest.NewMapStorer(values)}), url.Values{"path": {"/A/"}})
+
+			Convey("Then the path is normalised before it is matched", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Path, ShouldEqual, "/A/")
+				So(response.Target, ShouldEqual, "/c?edition=2024")
+			})
+		})
+
+		Convey("When a path that is not redirected is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/not-redirected"}})
+
+			Convey("Then the response status code should be 404", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When a path redirected in a loop is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/loop1"}})
+
+			Convey("Then the response status code should be 508", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusLoopDetected)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrResolveLoop.Error())
+			})
+		})
+
+		Convey("When a path redirected more times than the maximum number of hops is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/hop0"}})
+
+			Convey("Then the response status code should be 508", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusLoopDetected)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrTooManyHops.Error())
+			})
+		})
+
+		Convey("When a path redirected as many times as the maximum number of hops is resolved", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/hop1"}})
+
+			Convey("Then the end of the chain is returned", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusOK)
+
+				var response models.ResolvedRedirect
+				So(json.Unmarshal(responseRecorder.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Target, ShouldEqual, "/hop11")
+				So(response.Hops, ShouldHaveLength, 10)
+			})
+		})
+
+		Convey("When the path is missing or not relative", func() {
+			for _, path := range []string{"", "economy", "//example.com/economy"} {
+				responseRecorder := resolve(redirectAPI, url.Values{"path": {path}})
+
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidPath.Error())
+			}
+		})
+
+		Convey("When the host is not a valid hostname", func() {
+			responseRecorder := resolve(redirectAPI, url.Values{"path": {"/a"}, "host": {"CY.ONS.GOV.UK"}})
+
+			Convey("Then the response status code should be 400", func() {
+				So(responseRecorder.Code, ShouldEqual, http.StatusBadRequest)
+				So(responseRecorder.Body.String(), ShouldContainSubstring, api.ErrInvalidHost.Error())
+			})
+		})
+	})
+}
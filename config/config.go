@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/ONSdigital/dp-authorisation/v2/authorisation"
@@ -10,7 +12,14 @@ import (
 const (
 	RedisTLSProtocol = "TLS"
 
+	// RedirectChainPolicyReject rejects redirects that would create chains longer than the maximum depth
+	RedirectChainPolicyReject = "reject"
+	// RedirectChainPolicyWarn accepts redirects that would create chains longer than the maximum depth with a warning
+	RedirectChainPolicyWarn = "warn"
+
 	defaultBindAddr                   = "localhost:29900"
+	defaultBulkMaxBodySize            = 16 << 20
+	defaultBulkMaxItems               = 10000
 	defaultRedirectAPIURL             = "http://localhost:29900"
 	defaultGracefulShutdownTimeout    = 5 * time.Second
 	defaultHealthCheckInterval        = 30 * time.Second
@@ -20,30 +29,50 @@ const (
 	defaultOTServiceName              = "dis-redirect-api"
 	defaultOtelEnabled                = false
 	defaultRedisAddress               = "localhost:6379"
+	defaultRedirectChainMaxDepth      = 3
+	defaultRedirectChainPolicy        = RedirectChainPolicyWarn
+	defaultResolveMaxHops             = 10
 )
 
+var defaultExternalRedirectSchemes = []string{"https"}
+
 // Config represents service configuration for dis-redirect-api
 type Config struct {
 	BindAddr                   string        `envconfig:"BIND_ADDR"`
+	BulkMaxBodySize            int64         `envconfig:"BULK_MAX_BODY_SIZE"`
+	BulkMaxItems               int           `envconfig:"BULK_MAX_ITEMS"`
+	ExternalRedirectHosts      []string      `envconfig:"EXTERNAL_REDIRECT_HOSTS"`
+	ExternalRedirectSchemes    []string      `envconfig:"EXTERNAL_REDIRECT_SCHEMES"`
 	GracefulShutdownTimeout    time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
 	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
 	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	NormalisePaths             bool          `envconfig:"NORMALISE_PATHS"`
+	NormalisePathCase          bool          `envconfig:"NORMALISE_PATH_CASE"`
 	OTBatchTimeout             time.Duration `encconfig:"OTEL_BATCH_TIMEOUT"`
 	OTExporterOTLPEndpoint     string        `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
 	OTServiceName              string        `envconfig:"OTEL_SERVICE_NAME"`
 	OtelEnabled                bool          `envconfig:"OTEL_ENABLED"`
 	RedirectAPIURL             string        `envconfig:"REDIRECT_API_URL"`
+	RedirectChainMaxDepth      int           `envconfig:"REDIRECT_CHAIN_MAX_DEPTH"`
+	RedirectChainPolicy        string        `envconfig:"REDIRECT_CHAIN_POLICY"`
 	RedisAddress               string        `envconfig:"REDIS_ADDRESS"`
 	RedisClusterName           string        `envconfig:"REDIS_CLUSTER_NAME"`
 	RedisRegion                string        `envconfig:"REDIS_REGION"`
 	RedisSecProtocol           string        `envconfig:"REDIS_SEC_PROTO"`
 	RedisService               string        `envconfig:"REDIS_SERVICE"`
 	RedisUsername              string        `envconfig:"REDIS_USERNAME"`
+	ResolveMaxHops             int           `envconfig:"RESOLVE_MAX_HOPS"`
 	AuthorisationConfig        *authorisation.Config
 }
 
 var cfg *Config
 
+// ErrInvalidRedirectChainPolicy is returned when REDIRECT_CHAIN_POLICY is not one of the supported policies
+var ErrInvalidRedirectChainPolicy = errors.New("REDIRECT_CHAIN_POLICY must be '" + RedirectChainPolicyReject + "' or '" + RedirectChainPolicyWarn + "'")
+
+// ErrNotPositive is returned when a limit, such as BULK_MAX_ITEMS, is not greater than 0
+var ErrNotPositive = errors.New("must be greater than 0")
+
 // Get returns the default config with any modifications through environment
 // variables
 func Get() (*Config, error) {
@@ -53,10 +82,18 @@ func Get() (*Config, error) {
 
 	cfg = &Config{
 		BindAddr:                   defaultBindAddr,
+		BulkMaxBodySize:            defaultBulkMaxBodySize,
+		BulkMaxItems:               defaultBulkMaxItems,
+		ExternalRedirectHosts:      []string{},
+		ExternalRedirectSchemes:    defaultExternalRedirectSchemes,
 		RedirectAPIURL:             defaultRedirectAPIURL,
+		RedirectChainMaxDepth:      defaultRedirectChainMaxDepth,
+		RedirectChainPolicy:        defaultRedirectChainPolicy,
 		GracefulShutdownTimeout:    defaultGracefulShutdownTimeout,
 		HealthCheckInterval:        defaultHealthCheckInterval,
 		HealthCheckCriticalTimeout: defaultHealthCheckCriticalTimeout,
+		NormalisePaths:             false,
+		NormalisePathCase:          false,
 		OTBatchTimeout:             defaultOTBatchTimeout,
 		OTExporterOTLPEndpoint:     defaultOTExporterOTLPEndpoint,
 		OTServiceName:              defaultOTServiceName,
@@ -67,8 +104,39 @@ func Get() (*Config, error) {
 		RedisSecProtocol:           "",
 		RedisService:               "",
 		RedisUsername:              "",
+		ResolveMaxHops:             defaultResolveMaxHops,
 		AuthorisationConfig:        authorisation.NewDefaultConfig(),
 	}
 
-	return cfg, envconfig.Process("", cfg)
+	if err := envconfig.Process("", cfg); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.validate()
+}
+
+// validate checks the values that cannot be checked by their type alone
+func (c *Config) validate() error {
+	switch c.RedirectChainPolicy {
+	case RedirectChainPolicyReject, RedirectChainPolicyWarn:
+	default:
+		return fmt.Errorf("%w, not '%s'", ErrInvalidRedirectChainPolicy, c.RedirectChainPolicy)
+	}
+
+	limits := []struct {
+		name  string
+		value int64
+	}{
+		{"BULK_MAX_BODY_SIZE", c.BulkMaxBodySize},
+		{"BULK_MAX_ITEMS", int64(c.BulkMaxItems)},
+		{"REDIRECT_CHAIN_MAX_DEPTH", int64(c.RedirectChainMaxDepth)},
+		{"RESOLVE_MAX_HOPS", int64(c.ResolveMaxHops)},
+	}
+	for _, limit := range limits {
+		if limit.value <= 0 {
+			return fmt.Errorf("%s %w, not %d", limit.name, ErrNotPositive, limit.value)
+		}
+	}
+
+	return nil
 }
@@ -24,20 +24,29 @@ func TestConfig(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(configuration, ShouldResemble, &Config{
 					BindAddr:                   defaultBindAddr,
+					BulkMaxBodySize:            defaultBulkMaxBodySize,
+					BulkMaxItems:               defaultBulkMaxItems,
+					ExternalRedirectHosts:      []string{},
+					ExternalRedirectSchemes:    []string{"https"},
 					GracefulShutdownTimeout:    defaultGracefulShutdownTimeout,
 					HealthCheckInterval:        defaultHealthCheckInterval,
 					HealthCheckCriticalTimeout: defaultHealthCheckCriticalTimeout,
+					NormalisePaths:             false,
+					NormalisePathCase:          false,
 					OTBatchTimeout:             defaultOTBatchTimeout,
 					OTExporterOTLPEndpoint:     defaultOTExporterOTLPEndpoint,
 					OTServiceName:              defaultOTServiceName,
 					OtelEnabled:                defaultOtelEnabled,
 					RedirectAPIURL:             defaultRedirectAPIURL,
+					RedirectChainMaxDepth:      defaultRedirectChainMaxDepth,
+					RedirectChainPolicy:        defaultRedirectChainPolicy,
 					RedisAddress:               defaultRedisAddress,
 					RedisClusterName:           "",
 					RedisRegion:                "",
 					RedisSecProtocol:           "",
 					RedisService:               "",
 					RedisUsername:              "",
+					ResolveMaxHops:             defaultResolveMaxHops,
 					AuthorisationConfig:        authorisation.NewDefaultConfig(),
 				})
 			})
@@ -51,3 +60,52 @@ func TestConfig(t *testing.T) {
 		})
 	})
 }
+
+func TestConfigInvalidRedirectChainPolicy(t *testing.T) {
+	os.Clearenv()
+	cfg = nil
+	defer func() {
+		os.Clearenv()
+		cfg = nil
+	}()
+
+	Convey("Given an environment with an unknown redirect chain policy", t, func() {
+		So(os.Setenv("REDIRECT_CHAIN_POLICY", "ignore"), ShouldBeNil)
+
+		Convey("When the config values are retrieved", func() {
+			_, err := Get()
+
+			Convey("Then the policy is rejected", func() {
+				So(err, ShouldWrap, ErrInvalidRedirectChainPolicy)
+				So(err.Error(), ShouldContainSubstring, "ignore")
+			})
+		})
+	})
+}
+
+func TestConfigLimitsNotPositive(t *testing.T) {
+	defer func() {
+		os.Clearenv()
+		cfg = nil
+	}()
+
+	for _, name := range []string{"BULK_MAX_BODY_SIZE", "BULK_MAX_ITEMS", "REDIRECT_CHAIN_MAX_DEPTH", "RESOLVE_MAX_HOPS"} {
+		for _, value := range []string{"0", "-1"} {
+			Convey("Given an environment where "+name+" is "+value, t, func() {
+				os.Clearenv()
+				cfg = nil
+				So(os.Setenv(name, value), ShouldBeNil)
+
+				Convey("When the config values are retrieved", func() {
+					_, err := Get()
+
+					Convey("Then the limit is rejected", func() {
+						So(err, ShouldWrap, ErrNotPositive)
+						So(err.Error(), ShouldContainSubstring, name)
+						So(err.Error(), ShouldContainSubstring, value)
+					})
+				})
+			})
+		}
+	}
+}
@@ -9,11 +9,16 @@ Feature: Delete redirect endpoint
     Given I am an admin user
     And redis is healthy
     And redis contains no value for key "/economy/old-path"
+    And I set the "X-Request-Id" header to "test-request-id"
     When I DELETE "/v1/redirects/L2Vjb25vbXkvb2xkLXBhdGg="
     Then the HTTP status code should be "404"
-    And I should receive the following response:
+    And I should receive the following JSON response:
       """
-      not found
+      {
+        "code": "not_found",
+        "message": "not found",
+        "request_id": "test-request-id"
+      }
       """
 
   Scenario: Delete a redirect if the key exists
@@ -24,14 +29,27 @@ Feature: Delete redirect endpoint
     Then the HTTP status code should be "204"
     And redis contains no value for key "/economy/old-path"
 
+  Scenario: Delete a redirect by its path if the key exists
+    Given I am an admin user
+    And redis is healthy
+    And the key "/economy/old-path" is already set to a value of "/economy/new-path" in the Redis store
+    When I DELETE "/v1/redirects?path=/economy/old-path"
+    Then the HTTP status code should be "204"
+    And redis contains no value for key "/economy/old-path"
+
   Scenario: Delete a redirect with invalid base64 id
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I DELETE "/v1/redirects/L2Vjb25vbXkvb2xkLXBhdGgg=="
     Then the HTTP status code should be "400"
-    Then I should receive the following response:
+    Then I should receive the following JSON response:
       """
-      the base64 id provided is invalid
+      {
+        "code": "invalid_id",
+        "message": "the base64 id provided is invalid",
+        "request_id": "test-request-id"
+      }
       """
 
   Scenario: Delete a redirect without the correct permission
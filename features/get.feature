@@ -16,6 +16,10 @@ Feature: Redirect endpoint
         {
             "from": "/economy/old-path",
             "to": "/economy/new-path",
+            "type": "exact",
+            "status_code": 301,
+            "query_policy": "preserve",
+            "status": "active",
             "id": "L2Vjb25vbXkvb2xkLXBhdGg=",
             "links": {
                 "self": {
@@ -26,36 +30,99 @@ Feature: Redirect endpoint
             }
         """
 
-  Scenario: Return 400 when the key is not base64
+  Scenario: Return the value when the key is requested by its path
     Given I am an admin user
+    And the key "/economy/old-path" is already set to a value of "/economy/new-path" in the Redis store
     And redis is healthy
-    When I GET "/v1/redirects/cheese"
-    Then the HTTP status code should be "400"
-    And I should receive the following response:
+    When I GET "/v1/redirects?path=/economy/old-path"
+    Then I should receive the following JSON response with status "200":
         """
-            the base64 id provided is invalid
+        {
+            "from": "/economy/old-path",
+            "to": "/economy/new-path",
+            "type": "exact",
+            "status_code": 301,
+            "query_policy": "preserve",
+            "status": "active",
+            "id": "L2Vjb25vbXkvb2xkLXBhdGg=",
+            "links": {
+                "self": {
+                    "href": "http://localhost:29900/redirects/L2Vjb25vbXkvb2xkLXBhdGg=",
+                    "id": "L2Vjb25vbXkvb2xkLXBhdGg="
+                }
+              }
+            }
+        """
+
+  Scenario: Return the value when the key is requested by an id without padding
+    Given I am an admin user
+    And the key "/economy/old-path" is already set to a value of "/economy/new-path" in the Redis store
+    And redis is healthy
+    When I GET "/v1/redirects/L2Vjb25vbXkvb2xkLXBhdGg"
+    Then I should receive the following JSON response with status "200":
+        """
+        {
+            "from": "/economy/old-path",
+            "to": "/economy/new-path",
+            "type": "exact",
+            "status_code": 301,
+            "query_policy": "preserve",
+            "status": "active",
+            "id": "L2Vjb25vbXkvb2xkLXBhdGg=",
+            "links": {
+                "self": {
+                    "href": "http://localhost:29900/redirects/L2Vjb25vbXkvb2xkLXBhdGg=",
+                    "id": "L2Vjb25vbXkvb2xkLXBhdGg="
+                }
+              }
+            }
         """
 
+  Scenario: Return 400 when the key is not base64
+    Given I am an admin user
+    And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
+    When I GET "/v1/redirects/cheese!"
+    Then the HTTP status code should be "400"
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "invalid_id",
+        "message": "the base64 id provided is invalid",
+        "request_id": "test-request-id"
+      }
+      """
+
   Scenario: Return 404 when the key is not found
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects/b2xkLXBhdGg="
     Then the HTTP status code should be "404"
-    And I should receive the following response:
-        """
-            not found
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "not_found",
+        "message": "not found",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return 500 when redis returns an error
     Given I am an admin user
     And redis stops running
     And I wait 4 seconds to pass the critical timeout
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects/b2xkLXBhdGg="
     Then the HTTP status code should be "500"
-    And I should receive the following response:
-        """
-            internal error
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "internal_error",
+        "message": "internal error",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return all the redirects that exist in redis using default path parameters
     Given I am an admin user
@@ -96,50 +163,75 @@ Feature: Redirect endpoint
   Scenario: Return 400 when the count value given is not an integer
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects?count=not-a-number"
     Then the HTTP status code should be "400"
-    And I should receive the following response:
-        """
-            the count must be an integer giving the requested number of redirects
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "invalid_count",
+        "message": "the count must be an integer giving the requested number of redirects",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return 400 when the count value given is negative
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects?count=-5"
     Then the HTTP status code should be "400"
-    And I should receive the following response:
-            """
-                the count must be a positive integer
-            """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "negative_count",
+        "message": "the count must be a positive integer",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return 400 when the cursor value given is not an integer
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects?cursor=not-a-number"
     Then the HTTP status code should be "400"
-    And I should receive the following response:
-        """
-            the redirects cursor was invalid. It must be a positive integer
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "invalid_cursor",
+        "message": "the redirects cursor was invalid. It must be a positive integer",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return 400 when the cursor value given is negative
     Given I am an admin user
     And redis is healthy
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects?cursor=-6"
     Then the HTTP status code should be "400"
-    And I should receive the following response:
-        """
-            the redirects cursor was invalid. It must be a positive integer
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "invalid_cursor",
+        "message": "the redirects cursor was invalid. It must be a positive integer",
+        "request_id": "test-request-id"
+      }
+      """
 
   Scenario: Return 500 when calling get redirects with redis not running
     Given I am an admin user
     And redis stops running
     And I wait 4 seconds to pass the critical timeout
+    And I set the "X-Request-Id" header to "test-request-id"
     When I GET "/v1/redirects"
     Then the HTTP status code should be "500"
-    And I should receive the following response:
-        """
-            internal error
-        """
+    And I should receive the following JSON response:
+      """
+      {
+        "code": "internal_error",
+        "message": "internal error",
+        "request_id": "test-request-id"
+      }
+      """
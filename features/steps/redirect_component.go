@@ -91,7 +91,7 @@ func (c *RedirectComponent) DoGetHTTPServer(bindAddr string, router http.Handler
 }
 
 func (c *RedirectComponent) DoGetRedisClientOk(ctx context.Context, cfg *config.Config) (store.Redis, error) {
-	redisCli, err := disRedis.NewClient(ctx, &disRedis.ClientConfig{
+	redisCli, err := store.NewRedisClient(ctx, &disRedis.ClientConfig{
 		Address: cfg.RedisAddress,
 	})
 
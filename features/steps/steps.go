@@ -26,6 +26,7 @@ func (c *RedirectComponent) RegisterSteps(ctx *godog.ScenarioContext) {
 	ctx.Step(`^in each redirect I would expect the response to contain values that have these structures$`, c.inEachRedirectIWouldExpectTheResponseToContainValuesThatHaveTheseStructures)
 	ctx.Step(`^the list of redirects should also contain the following values:$`, c.theListOfRedirectsShouldAlsoContainTheFollowingValues)
 	ctx.Step(`^I would expect there to be (\d+) redirects returned in a list$`, c.iWouldExpectThereToBeRedirectsReturnedInAList)
+	ctx.Step(`^the key "([^"]*)" holds a redirect to "([^"]*)" with status code (\d+) in the Redis store$`, c.theKeyHoldsARedirectToWithStatusCodeInTheRedisStore)
 }
 
 func (c *RedirectComponent) theRedirectAPIIsRunning() error {
@@ -108,7 +109,7 @@ func (c *RedirectComponent) checkStructure(responseRedirect *models.Redirect) er
 	from := responseRedirect.From
 	assert.NotEmpty(&c.ErrorFeature, from)
 	assert.NotEmpty(&c.ErrorFeature, responseRedirect.To)
-	encodedFrom := base64.StdEncoding.EncodeToString([]byte(from))
+	encodedFrom := base64.URLEncoding.EncodeToString([]byte(responseRedirect.Key()))
 	assert.Equal(&c.ErrorFeature, encodedFrom, responseRedirect.ID)
 	expectedSelfHref := "https://api.beta.ons.gov.uk/v1/redirects/" + encodedFrom
 	assert.Equal(&c.ErrorFeature, expectedSelfHref, responseRedirect.Links.Self.Href)
@@ -166,3 +167,20 @@ func (c *RedirectComponent) iWouldExpectThereToBeRedirectsReturnedInAList(expect
 
 	return nil
 }
+
+func (c *RedirectComponent) theKeyHoldsARedirectToWithStatusCodeInTheRedisStore(key, expectedTo string, expectedStatusCode int) error {
+	value, err := c.redisFeature.Client.Get(context.Background(), key).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get key %q from Redis: %w", key, err)
+	}
+
+	var stored models.Redirect
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal stored redirect: %w", err)
+	}
+
+	assert.Equal(&c.ErrorFeature, expectedTo, stored.To)
+	assert.Equal(&c.ErrorFeature, expectedStatusCode, stored.StatusCode)
+
+	return nil
+}
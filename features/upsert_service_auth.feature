@@ -18,7 +18,7 @@ Feature: Upsert redirect endpoint with service auth
           }
         """
     Then the HTTP status code should be "201"
-    And the key "/economy/old-path" has a value of "/economy/new-path" in the Redis store
+    And the key "/economy/old-path" holds a redirect to "/economy/new-path" with status code 301 in the Redis store
 
   Scenario: Upsert a redirect value via PUT if the key and value already exist
     Given redis is healthy
@@ -32,7 +32,7 @@ Feature: Upsert redirect endpoint with service auth
           }
         """
     Then the HTTP status code should be "200"
-    And the key "/economy/old-path" has a value of "/economy/new-path" in the Redis store
+    And the key "/economy/old-path" holds a redirect to "/economy/new-path" with status code 301 in the Redis store
 
   Scenario: Upsert a redirect value via PUT with invalid base64 id
     Given redis is healthy
@@ -17,7 +17,8 @@ Feature: Upsert redirect endpoint with user auth
           }
         """
       Then the HTTP status code should be "201"
-      And the key "/economy/old-path" has a value of "/economy/new-path" in the Redis store
+      And the response header "Location" should be "http://localhost:29900/redirects/L2Vjb25vbXkvb2xkLXBhdGg="
+      And the key "/economy/old-path" holds a redirect to "/economy/new-path" with status code 301 in the Redis store
 
     Scenario: Upsert a redirect value via PUT if the key and value already exist
       Given redis is healthy
@@ -31,7 +32,21 @@ Feature: Upsert redirect endpoint with user auth
           }
         """
       Then the HTTP status code should be "200"
-      And the key "/economy/old-path" has a value of "/economy/new-path" in the Redis store
+      And the key "/economy/old-path" holds a redirect to "/economy/new-path" with status code 301 in the Redis store
+
+    Scenario: Upsert a redirect value via PUT that would create a loop
+      Given redis is healthy
+      And I am an admin user
+      And the key "/economy/new-path" is already set to a value of "/economy/old-path" in the Redis store
+      When I PUT "/v1/redirects/L2Vjb25vbXkvb2xkLXBhdGg="
+        """
+          {
+            "from": "/economy/old-path",
+            "to": "/economy/new-path"
+          }
+        """
+      Then the HTTP status code should be "400"
+      And redis contains no value for key "/economy/old-path"
 
     Scenario: Upsert a redirect value via PUT with invalid base64 id
       Given redis is healthy
@@ -0,0 +1,101 @@
+package models
+
+// The outcomes of importing a single redirect
+const (
+	ImportResultCreated  = "created"
+	ImportResultUpdated  = "updated"
+	ImportResultRejected = "rejected"
+	ImportResultSkipped  = "skipped"
+)
+
+// ImportReport represents response body when importing redirects in bulk
+type ImportReport struct {
+	Count    int            `json:"count"`
+	Created  int            `json:"created"`
+	Updated  int            `json:"updated"`
+	Rejected int            `json:"rejected"`
+	Skipped  int            `json:"skipped"`
+	Items    []ImportResult `json:"items"`
+}
+
+// ImportResult is the outcome of importing a single redirect. Rows are numbered from 1, in the order they
+// appear in the request, not counting the header of a CSV import.
+type ImportResult struct {
+	Row     int    `json:"row"`
+	Host    string `json:"host,omitempty"`
+	From    string `json:"from,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Result  string `json:"result"`
+	Error   string `json:"error,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// Add records the outcome of importing a single redirect in the report
+func (r *ImportReport) Add(result ImportResult) {
+	r.Count++
+	switch result.Result {
+	case ImportResultCreated:
+		r.Created++
+	case ImportResultUpdated:
+		r.Updated++
+	case ImportResultRejected:
+		r.Rejected++
+	case ImportResultSkipped:
+		r.Skipped++
+	}
+	r.Items = append(r.Items, result)
+}
+
+// The outcomes of deleting a single redirect
+const (
+	DeleteResultDeleted     = "deleted"
+	DeleteResultWouldDelete = "would_delete"
+	DeleteResultNotFound    = "not_found"
+	DeleteResultRejected    = "rejected"
+)
+
+// BulkDelete represents request body when deleting redirects in bulk. Either the ids and paths of the redirects
+// are listed, or a prefix selects them. The host applies to the paths and the prefix, as an id includes its host.
+// Without a host, the paths and the prefix select global redirects, unless AllHosts is set for a prefix to select
+// the redirects on every host as well.
+type BulkDelete struct {
+	IDs      []string `json:"ids,omitempty"`
+	Paths    []string `json:"paths,omitempty"`
+	Host     string   `json:"host,omitempty"`
+	Prefix   string   `json:"prefix,omitempty"`
+	AllHosts bool     `json:"all_hosts,omitempty"`
+}
+
+// DeleteReport represents response body when deleting redirects in bulk. In a dry run, nothing is deleted and
+// Deleted counts the redirects that would be.
+type DeleteReport struct {
+	Count    int            `json:"count"`
+	Deleted  int            `json:"deleted"`
+	NotFound int            `json:"not_found"`
+	Rejected int            `json:"rejected"`
+	DryRun   bool           `json:"dry_run"`
+	Items    []DeleteResult `json:"items"`
+}
+
+// DeleteResult is the outcome of deleting a single redirect
+type DeleteResult struct {
+	ID     string `json:"id"`
+	Host   string `json:"host,omitempty"`
+	From   string `json:"from,omitempty"`
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Add records the outcome of deleting a single redirect in the report
+func (r *DeleteReport) Add(result DeleteResult) {
+	r.Count++
+	switch result.Result {
+	case DeleteResultDeleted, DeleteResultWouldDelete:
+		r.Deleted++
+	case DeleteResultNotFound:
+		r.NotFound++
+	case DeleteResultRejected:
+		r.Rejected++
+	}
+	r.Items = append(r.Items, result)
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestImportReportAdd(t *testing.T) {
+	Convey("Given an empty import report", t, func() {
+		report := ImportReport{}
+
+		Convey("When the outcome of importing each redirect is added", func() {
+			report.Add(ImportResult{Row: 1, Result: ImportResultCreated})
+			report.Add(ImportResult{Row: 2, Result: ImportResultUpdated})
+			report.Add(ImportResult{Row: 3, Result: ImportResultRejected, Error: "invalid"})
+			report.Add(ImportResult{Row: 4, Result: ImportResultSkipped})
+			report.Add(ImportResult{Row: 5, Result: ImportResultCreated})
+
+			Convey("Then each outcome is counted", func() {
+				So(report.Count, ShouldEqual, 5)
+				So(report.Created, ShouldEqual, 2)
+				So(report.Updated, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Skipped, ShouldEqual, 1)
+			})
+
+			Convey("And listed in order", func() {
+				So(report.Items, ShouldHaveLength, 5)
+				So(report.Items[2].Row, ShouldEqual, 3)
+				So(report.Items[2].Error, ShouldEqual, "invalid")
+			})
+		})
+	})
+}
+
+func TestDeleteReportAdd(t *testing.T) {
+	Convey("Given an empty delete report", t, func() {
+		report := DeleteReport{}
+
+		Convey("When the outcome of deleting each redirect is added", func() {
+			report.Add(DeleteResult{ID: "a", Result: DeleteResultDeleted})
+			report.Add(DeleteResult{ID: "b", Result: DeleteResultWouldDelete})
+			report.Add(DeleteResult{ID: "c", Result: DeleteResultNotFound})
+			report.Add(DeleteResult{ID: "d", Result: DeleteResultRejected, Error: "invalid"})
+
+			Convey("Then each outcome is counted, counting the redirects that would be deleted as deleted", func() {
+				So(report.Count, ShouldEqual, 4)
+				So(report.Deleted, ShouldEqual, 2)
+				So(report.NotFound, ShouldEqual, 1)
+				So(report.Rejected, ShouldEqual, 1)
+				So(report.Items, ShouldHaveLength, 4)
+			})
+		})
+	})
+}
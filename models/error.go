@@ -0,0 +1,66 @@
+package models
+
+// ErrorResponse represents the body of every error response, identifying the error with a code that does not
+// change between releases, unlike the message, which is written for people to read. Existing is only set for a
+// redirect_exists error from a request to only create a redirect, and is the redirect that already exists.
+type ErrorResponse struct {
+	Code      string    `json:"code"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"request_id,omitempty"`
+	Existing  *Redirect `json:"existing,omitempty"`
+}
+
+// The codes identifying each error the API can return
+const (
+	ErrorCodeInternal               = "internal_error"
+	ErrorCodeNotFound               = "not_found"
+	ErrorCodeNegativeCount          = "negative_count"
+	ErrorCodeInvalidCount           = "invalid_count"
+	ErrorCodeInvalidCursor          = "invalid_cursor"
+	ErrorCodeInvalidCollapseChains  = "invalid_collapse_chains"
+	ErrorCodeInvalidAtomic          = "invalid_atomic"
+	ErrorCodeInvalidDryRun          = "invalid_dry_run"
+	ErrorCodeUnsupportedMediaType   = "unsupported_media_type"
+	ErrorCodeInvalidExportFormat    = "invalid_export_format"
+	ErrorCodeUnsupportedPatchType   = "unsupported_patch_type"
+	ErrorCodeTooManyItems           = "too_many_items"
+	ErrorCodeRequestBodyTooLarge    = "request_body_too_large"
+	ErrorCodeInvalidCSV             = "invalid_csv"
+	ErrorCodeInvalidImportColumns   = "invalid_import_columns"
+	ErrorCodeInvalidImportRow       = "invalid_import_row"
+	ErrorCodeInvalidImportValue     = "invalid_import_value"
+	ErrorCodeDuplicateImportRow     = "duplicate_import_row"
+	ErrorCodeInvalidBulkDelete      = "invalid_bulk_delete"
+	ErrorCodeInvalidDeletePath      = "invalid_delete_path"
+	ErrorCodeInvalidAllHosts        = "invalid_all_hosts"
+	ErrorCodeDuplicateDeleteItem    = "duplicate_delete_item"
+	ErrorCodeInvalidID              = "invalid_id"
+	ErrorCodeInvalidRequestBody     = "invalid_request_body"
+	ErrorCodeInvalidMergePatch      = "invalid_merge_patch"
+	ErrorCodeInvalidPrefix          = "invalid_prefix"
+	ErrorCodeInvalidPath            = "invalid_path"
+	ErrorCodeInvalidRedirectPath    = "invalid_redirect_path"
+	ErrorCodeResolveLoop            = "resolve_loop"
+	ErrorCodeTooManyHops            = "too_many_hops"
+	ErrorCodePreconditionFailed     = "precondition_failed"
+	ErrorCodeRedirectExists         = "redirect_exists"
+	ErrorCodeWriteConflict          = "write_conflict"
+	ErrorCodeIDFromMismatch         = "id_from_mismatch"
+	ErrorCodeInvalidHost            = "invalid_host"
+	ErrorCodeFromToNotRelative      = "from_to_not_relative"
+	ErrorCodeExternalHostNotAllowed = "external_host_not_allowed"
+	ErrorCodeCircularPaths          = "circular_paths"
+	ErrorCodeRedirectLoop           = "redirect_loop"
+	ErrorCodeRedirectChainTooLong   = "redirect_chain_too_long"
+	ErrorCodeInvalidRedirectType    = "invalid_redirect_type"
+	ErrorCodeInvalidPrefixRedirect  = "invalid_prefix_redirect"
+	ErrorCodeUnexpectedWildcard     = "unexpected_wildcard"
+	ErrorCodeInvalidRegexRedirect   = "invalid_regex_redirect"
+	ErrorCodeInvalidRegexPattern    = "invalid_regex_pattern"
+	ErrorCodeInvalidStatusCode      = "invalid_status_code"
+	ErrorCodeInvalidQueryPolicy     = "invalid_query_policy"
+	ErrorCodeInvalidTTL             = "invalid_ttl"
+	ErrorCodeTTLAndExpiresAt        = "ttl_and_expires_at"
+	ErrorCodeExpiresAtInPast        = "expires_at_in_past"
+	ErrorCodeInvalidValidityWindow  = "invalid_validity_window"
+)
@@ -1,7 +1,9 @@
 package models
 
 const (
-	LogRedirectIDKey   = "redirect_id"
-	LogRedirectFromKey = "redirect_from"
-	LogRedirectToKey   = "redirect_to"
+	LogRedirectIDKey         = "redirect_id"
+	LogRedirectHostKey       = "redirect_host"
+	LogRedirectFromKey       = "redirect_from"
+	LogRedirectToKey         = "redirect_to"
+	LogRedirectStatusCodeKey = "redirect_status_code"
 )
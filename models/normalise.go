@@ -0,0 +1,61 @@
+package models
+
+import (
+	"path"
+	"strings"
+)
+
+const upperHex = "0123456789ABCDEF"
+
+// NormalisePath returns the canonical form of the given path, so that variants of the same path are stored
+// against the same key. Percent-encoded unreserved characters are decoded and all other percent-encodings
+// use uppercase hex digits, dot segments and repeated slashes are removed, as is any trailing slash. When
+// foldCase is true the path is also lowercased.
+func NormalisePath(p string, foldCase bool) string {
+	if foldCase {
+		p = strings.ToLower(p)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		if p[i] == '%' && i+2 < len(p) && isHex(p[i+1]) && isHex(p[i+2]) {
+			c := unhex(p[i+1])<<4 | unhex(p[i+2])
+			switch {
+			case isUnreserved(c) && foldCase:
+				b.WriteString(strings.ToLower(string(c)))
+			case isUnreserved(c):
+				b.WriteByte(c)
+			default:
+				b.WriteByte('%')
+				b.WriteByte(upperHex[c>>4])
+				b.WriteByte(upperHex[c&15])
+			}
+			i += 2
+			continue
+		}
+		b.WriteByte(p[i])
+	}
+
+	return path.Clean(b.String())
+}
+
+// isUnreserved returns true for the characters that RFC 3986 says never need to be percent-encoded
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
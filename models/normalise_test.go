@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNormalisePath(t *testing.T) {
+	Convey("Given paths that are variants of the same path", t, func() {
+		Convey("Then a trailing slash is removed", func() {
+			So(NormalisePath("/economy/old-path/", false), ShouldEqual, "/economy/old-path")
+		})
+
+		Convey("Then the root path is kept", func() {
+			So(NormalisePath("/", false), ShouldEqual, "/")
+		})
+
+		Convey("Then dot segments and repeated slashes are removed", func() {
+			So(NormalisePath("/economy/./inflation/../old-path//data", false), ShouldEqual, "/economy/old-path/data")
+		})
+
+		Convey("Then percent-encoded unreserved characters are decoded", func() {
+			So(NormalisePath("/economy/old%2Dpath%7E", false), ShouldEqual, "/economy/old-path~")
+		})
+
+		Convey("Then other percent-encodings use uppercase hex digits", func() {
+			So(NormalisePath("/economy/old%2fpath%c3%a9", false), ShouldEqual, "/economy/old%2Fpath%C3%A9")
+		})
+
+		Convey("Then percent-encoded dot segments are removed", func() {
+			So(NormalisePath("/economy/%2e%2E/business", false), ShouldEqual, "/business")
+		})
+
+		Convey("Then the case of the path is kept unless it is folded", func() {
+			So(NormalisePath("/Economy/Old-Path", false), ShouldEqual, "/Economy/Old-Path")
+			So(NormalisePath("/Economy/Old-Path%4D%2f", true), ShouldEqual, "/economy/old-pathm%2F")
+		})
+
+		Convey("Then the wildcard of a prefix path is kept", func() {
+			So(NormalisePath("/Economy/inflation/*", true), ShouldEqual, "/economy/inflation/*")
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"sync"
+)
+
+// RegexAnchor is the prefix that the 'from' pattern of a regex redirect must start with, so that patterns are
+// always matched from the start of the requested path
+const RegexAnchor = "^/"
+
+// MaxPatternLength is the maximum number of characters allowed in the 'from' pattern of a regex redirect
+const MaxPatternLength = 512
+
+// MaxPatternComplexity is the maximum number of instructions allowed in the compiled program of a regex
+// redirect's pattern. Patterns are matched in time linear to the length of the path and the size of this
+// program, so limiting it bounds the time taken to match any single redirect.
+const MaxPatternComplexity = 2000
+
+// A list of errors returned when validating regex redirect patterns
+var (
+	ErrPatternTooLong      = fmt.Errorf("pattern must be no longer than %d characters", MaxPatternLength)
+	ErrPatternTooComplex   = errors.New("pattern is too complex")
+	ErrUnknownCaptureGroup = errors.New("substitution refers to a capture group that is not in the pattern")
+)
+
+// compiledPatterns caches the compiled form of regex redirect patterns so that they are only compiled once
+var compiledPatterns sync.Map
+
+// CompilePattern validates and compiles the 'from' pattern of a regex redirect, rejecting patterns that are
+// too long or too complex to be matched quickly
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := compiledPatterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	if len(pattern) > MaxPatternLength {
+		return nil, ErrPatternTooLong
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(prog.Inst) > MaxPatternComplexity {
+		return nil, ErrPatternTooComplex
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	compiledPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// ValidateSubstitution checks that every '$1' or '${name}' style reference in the given template refers to a
+// capture group in the compiled pattern
+func ValidateSubstitution(re *regexp.Regexp, template string) error {
+	for _, name := range substitutionReferences(template) {
+		if n, err := strconv.Atoi(name); err == nil {
+			if n > re.NumSubexp() {
+				return ErrUnknownCaptureGroup
+			}
+			continue
+		}
+
+		if re.SubexpIndex(name) < 0 {
+			return ErrUnknownCaptureGroup
+		}
+	}
+
+	return nil
+}
+
+// substitutionReferences returns the names of the capture groups referred to in the given template, following
+// the same rules as regexp.Expand
+func substitutionReferences(template string) []string {
+	var names []string
+
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 == len(template) {
+			continue
+		}
+
+		i++
+		if template[i] == '$' {
+			continue
+		}
+
+		braced := template[i] == '{'
+		if braced {
+			i++
+		}
+
+		start := i
+		for i < len(template) && isNameByte(template[i]) {
+			i++
+		}
+
+		if i > start && (!braced || (i < len(template) && template[i] == '}')) {
+			names = append(names, template[start:i])
+		}
+
+		if !braced {
+			i--
+		}
+	}
+
+	return names
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompilePattern(t *testing.T) {
+	Convey("Given a valid pattern", t, func() {
+		pattern := `^/datasets/(\w+)/editions/(?P<edition>\d{4})$`
+
+		Convey("When it is compiled", func() {
+			re, err := CompilePattern(pattern)
+
+			Convey("Then the compiled pattern is returned", func() {
+				So(err, ShouldBeNil)
+				So(re.NumSubexp(), ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given a pattern with invalid syntax", t, func() {
+		pattern := `^/datasets/(\w+`
+
+		Convey("When it is compiled", func() {
+			_, err := CompilePattern(pattern)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a pattern that is too long", t, func() {
+		pattern := "^/" + strings.Repeat("a", MaxPatternLength)
+
+		Convey("When it is compiled", func() {
+			_, err := CompilePattern(pattern)
+
+			Convey("Then a pattern too long error is returned", func() {
+				So(err, ShouldEqual, ErrPatternTooLong)
+			})
+		})
+	})
+
+	Convey("Given a pattern that is too complex", t, func() {
+		pattern := `^/(((a{1,100}){1,100}){1,100})$`
+
+		Convey("When it is compiled", func() {
+			_, err := CompilePattern(pattern)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a short pattern that compiles to a large program", t, func() {
+		pattern := `^/(\w{1,50}-){1,20}$`
+
+		Convey("When it is compiled", func() {
+			_, err := CompilePattern(pattern)
+
+			Convey("Then a pattern too complex error is returned", func() {
+				So(err, ShouldEqual, ErrPatternTooComplex)
+			})
+		})
+	})
+}
+
+func TestValidateSubstitution(t *testing.T) {
+	re, err := CompilePattern(`^/datasets/(\w+)/editions/(?P<edition>\d{4})$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given a target that refers to numbered and named capture groups", t, func() {
+		Convey("Then it is valid", func() {
+			So(ValidateSubstitution(re, "/datasets/$1/${edition}/${2}"), ShouldBeNil)
+		})
+	})
+
+	Convey("Given a target with an escaped dollar sign", t, func() {
+		Convey("Then it is valid", func() {
+			So(ValidateSubstitution(re, "/prices/$$5"), ShouldBeNil)
+		})
+	})
+
+	Convey("Given a target that refers to a capture group number that does not exist", t, func() {
+		Convey("Then an unknown capture group error is returned", func() {
+			So(ValidateSubstitution(re, "/datasets/$3"), ShouldEqual, ErrUnknownCaptureGroup)
+		})
+	})
+
+	Convey("Given a target that refers to a capture group name that does not exist", t, func() {
+		Convey("Then an unknown capture group error is returned", func() {
+			So(ValidateSubstitution(re, "/datasets/${release}"), ShouldEqual, ErrUnknownCaptureGroup)
+		})
+	})
+}
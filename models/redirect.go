@@ -1,11 +1,184 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultStatusCode is the HTTP status code used for redirects that do not specify one
+const DefaultStatusCode = http.StatusMovedPermanently
+
+// The types of redirect rule
+const (
+	RedirectTypeExact  = "exact"
+	RedirectTypePrefix = "prefix"
+	RedirectTypeRegex  = "regex"
+)
+
+// The policies for what happens to the query string of a request when it is redirected
+const (
+	QueryPolicyPreserve = "preserve"
+	QueryPolicyDrop     = "drop"
+	QueryPolicyMerge    = "merge"
+	QueryPolicyReplace  = "replace"
+)
+
+// DefaultQueryPolicy is the query policy used for redirects that do not specify one
+const DefaultQueryPolicy = QueryPolicyPreserve
+
+// PrefixWildcard is the suffix that marks the 'from' path of a prefix redirect, and the 'to' path of a
+// prefix redirect that keeps the remainder of the requested path
+const PrefixWildcard = "/*"
+
+// HostKeyPrefix is the prefix of the keys of redirects that are scoped to a host. The key of a host scoped
+// redirect is the prefix followed by the host and the 'from' path, like a protocol relative URL, so it can
+// never clash with the key of a global redirect, which always starts with a single '/' or the regex anchor.
+const HostKeyPrefix = "//"
+
+// validQueryPolicies is the set of query policies a redirect is allowed to use
+var validQueryPolicies = map[string]bool{
+	QueryPolicyPreserve: true,
+	QueryPolicyDrop:     true,
+	QueryPolicyMerge:    true,
+	QueryPolicyReplace:  true,
+}
+
+// validHost matches lowercase hostnames, optionally followed by a port
+var validHost = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*(:[0-9]{1,5})?$`)
+
+// The activation states of a redirect, depending on its validity window
+const (
+	RedirectStatusActive    = "active"
+	RedirectStatusScheduled = "scheduled"
+	RedirectStatusEnded     = "ended"
+)
+
+// validStatusCodes is the set of HTTP status codes a redirect is allowed to use
+var validStatusCodes = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
 // Redirect represents response body when retrieving a redirect
 type Redirect struct {
-	From  string        `json:"from,omitempty"`
-	To    string        `json:"to,omitempty"`
-	ID    string        `json:"id"`
-	Links RedirectLinks `json:"links"`
+	Host        string        `json:"host,omitempty"`
+	From        string        `json:"from,omitempty"`
+	To          string        `json:"to,omitempty"`
+	Type        string        `json:"type,omitempty"`
+	StatusCode  int           `json:"status_code,omitempty"`
+	QueryPolicy string        `json:"query_policy,omitempty"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+	UpdatedBy   string        `json:"updated_by,omitempty"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
+	TTL         int64         `json:"ttl,omitempty"`
+	ValidFrom   *time.Time    `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time    `json:"valid_until,omitempty"`
+	Status      string        `json:"status,omitempty"`
+	ID          string        `json:"id"`
+	Links       RedirectLinks `json:"links"`
+}
+
+// IsValidStatusCode returns true if the given code is one of the allowed redirect status codes
+func IsValidStatusCode(code int) bool {
+	return validStatusCodes[code]
+}
+
+// IsValidQueryPolicy returns true if the given policy is one of the allowed query policies
+func IsValidQueryPolicy(policy string) bool {
+	return validQueryPolicies[policy]
+}
+
+// IsValidHost returns true if the given host is a lowercase hostname, optionally followed by a port
+func IsValidHost(host string) bool {
+	return validHost.MatchString(host)
+}
+
+// RedirectKey returns the key that a redirect from the given path is stored against. Redirects without a host
+// are global and keyed by their 'from' path alone.
+func RedirectKey(host, from string) string {
+	if host == "" {
+		return from
+	}
+
+	return HostKeyPrefix + host + from
+}
+
+// ParseRedirectKey returns the host and 'from' path of the redirect stored against the given key
+func ParseRedirectKey(key string) (host, from string) {
+	if !strings.HasPrefix(key, HostKeyPrefix) {
+		return "", key
+	}
+
+	scoped := strings.TrimPrefix(key, HostKeyPrefix)
+	i := strings.IndexAny(scoped, "/^")
+	if i < 0 {
+		return scoped, ""
+	}
+
+	return scoped[:i], scoped[i:]
+}
+
+// RedirectID returns the id of the redirect stored against the given key, which is the key encoded as URL safe
+// base64 with padding
+func RedirectID(key string) string {
+	return base64.URLEncoding.EncodeToString([]byte(key))
+}
+
+// ParseRedirectID returns the key of the redirect with the given id. Ids are URL safe base64, and are accepted
+// with or without padding.
+func ParseRedirectID(id string) (string, error) {
+	encoding := base64.RawURLEncoding
+	if strings.HasSuffix(id, "=") {
+		encoding = base64.URLEncoding
+	}
+
+	key, err := encoding.DecodeString(id)
+	if err != nil {
+		return "", err
+	}
+
+	return string(key), nil
+}
+
+// Key returns the key that the redirect is stored against
+func (r *Redirect) Key() string {
+	return RedirectKey(r.Host, r.From)
+}
+
+// ETag returns a strong entity tag for the stored state of the redirect, which changes whenever the redirect
+// is saved. The fields that are worked out when the redirect is returned, such as its links and remaining TTL,
+// are left out so that the tag does not change between requests.
+func (r *Redirect) ETag() string {
+	stored := *r
+	stored.ID = ""
+	stored.Links = RedirectLinks{}
+	stored.TTL = 0
+	stored.Status = ""
+
+	// a redirect always marshals to JSON, as its times have been parsed from or are written as JSON
+	value, _ := json.Marshal(stored)
+	sum := sha256.Sum256(value)
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// TargetPath returns the given 'to' path or URL without any query string or fragment, which is the page a
+// redirect to it lands on
+func TargetPath(to string) string {
+	path, _, _ := strings.Cut(to, "?")
+	path, _, _ = strings.Cut(path, "#")
+	return path
 }
 
 // Redirects represents response body when retrieving a list of redirects
@@ -17,6 +190,40 @@ type Redirects struct {
 	TotalCount   int        `json:"total_count"`
 }
 
+// RedirectCollisions represents response body when reporting the redirects whose keys collide or change once
+// their paths are normalised
+type RedirectCollisions struct {
+	Count int                 `json:"count"`
+	Items []RedirectCollision `json:"items"`
+}
+
+// RedirectCollision is a group of redirects that share the same key once their paths are normalised, or a single
+// redirect whose key is not normalised
+type RedirectCollision struct {
+	Key       string     `json:"key"`
+	Redirects []Redirect `json:"redirects"`
+}
+
+// CollapsedRedirects represents response body when reporting the redirects that were changed to point straight
+// at the end of their chain
+type CollapsedRedirects struct {
+	Count int        `json:"count"`
+	Items []Redirect `json:"items"`
+}
+
+// CollapsingRedirect represents response body when a redirect is saved with chains collapsed, being the stored
+// redirect along with the report of the redirects that were changed to point straight at the end of its chain.
+// There is no report if the chains could not be collapsed.
+type CollapsingRedirect struct {
+	Redirect
+	Collapsed *CollapsedRedirects `json:"collapsed,omitempty"`
+}
+
+// RebuiltIndexes represents response body when reporting how many redirects were added to the indexes
+type RebuiltIndexes struct {
+	Count int `json:"count"`
+}
+
 // RedirectLinks is a type that contains links relating to the individual redirect.
 // Currently, it only contains one link, which is a link to itself.
 type RedirectLinks struct {
@@ -28,3 +235,172 @@ type RedirectSelf struct {
 	Href string `json:"href"`
 	ID   string `json:"id"`
 }
+
+// RemainingTTL returns the number of seconds, rounded up, until the redirect expires. Zero is returned
+// if the redirect does not expire.
+func (r *Redirect) RemainingTTL(now time.Time) int64 {
+	if r.ExpiresAt == nil {
+		return 0
+	}
+
+	remaining := r.ExpiresAt.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+
+	return int64((remaining + time.Second - 1) / time.Second)
+}
+
+// ActivationStatus returns whether the redirect is active, scheduled to start or has ended at the given time
+func (r *Redirect) ActivationStatus(now time.Time) string {
+	if r.ValidFrom != nil && now.Before(*r.ValidFrom) {
+		return RedirectStatusScheduled
+	}
+
+	if r.ValidUntil != nil && !now.Before(*r.ValidUntil) {
+		return RedirectStatusEnded
+	}
+
+	return RedirectStatusActive
+}
+
+// IsActive returns true if the given time falls within the validity window of the redirect
+func (r *Redirect) IsActive(now time.Time) bool {
+	return r.ActivationStatus(now) == RedirectStatusActive
+}
+
+// IsPermanent returns true if the redirect uses a permanent status code and has no expiry time or validity window,
+// so that it can be relied on to keep sending its 'from' path to its target
+func (r *Redirect) IsPermanent() bool {
+	if r.ExpiresAt != nil || r.ValidFrom != nil || r.ValidUntil != nil {
+		return false
+	}
+
+	switch r.StatusCode {
+	case 0, http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// TargetFor returns the path that the given requested path should be redirected to by this redirect.
+// For prefix redirects whose 'to' path ends with the wildcard, the part of the requested path after the
+// prefix is appended to the target. For regex redirects, '$1' style references in the 'to' path are
+// replaced with the groups captured from the requested path.
+func (r *Redirect) TargetFor(path string) string {
+	if r.Type == RedirectTypeRegex {
+		return r.expandTarget(path)
+	}
+
+	if r.Type != RedirectTypePrefix || !strings.HasSuffix(r.To, PrefixWildcard) {
+		return r.To
+	}
+
+	prefix := strings.TrimSuffix(r.From, PrefixWildcard)
+	target := strings.TrimSuffix(r.To, PrefixWildcard)
+	remainder := strings.TrimPrefix(path, prefix)
+
+	if target == "" && remainder == "" {
+		return "/"
+	}
+
+	return target + remainder
+}
+
+// MatchesPath returns true if the given path is matched by the 'from' pattern of a regex redirect
+func (r *Redirect) MatchesPath(path string) bool {
+	if r.Type != RedirectTypeRegex {
+		return false
+	}
+
+	re, err := CompilePattern(r.From)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(path)
+}
+
+// expandTarget substitutes the groups captured from the given path by the 'from' pattern of a regex redirect
+// into its 'to' path. Captured groups could otherwise turn a relative target into a protocol relative one
+// such as "//host", so repeated leading slashes are collapsed to keep the target on this site.
+func (r *Redirect) expandTarget(path string) string {
+	re, err := CompilePattern(r.From)
+	if err != nil {
+		return r.To
+	}
+
+	match := re.FindStringSubmatchIndex(path)
+	if match == nil {
+		return r.To
+	}
+
+	target := string(re.ExpandString(nil, r.To, path, match))
+	if !strings.HasPrefix(r.To, "/") {
+		return target
+	}
+
+	return "/" + strings.TrimLeft(target, "/")
+}
+
+// ResolveTarget returns the URL that a request for the given path and raw query string should be redirected to,
+// applying the query policy of the redirect to the query of the target and the query of the request:
+//   - preserve keeps the query of the target and appends the query of the request to it
+//   - drop keeps the query of the target and discards the query of the request
+//   - merge combines both queries, with parameters from the request replacing those of the same name
+//   - replace discards the query of the target and uses the query of the request instead
+func (r *Redirect) ResolveTarget(path, rawQuery string) string {
+	target, fragment, hasFragment := strings.Cut(r.TargetFor(path), "#")
+	target, targetQuery, _ := strings.Cut(target, "?")
+
+	var query string
+	switch r.QueryPolicy {
+	case QueryPolicyDrop:
+		query = targetQuery
+	case QueryPolicyReplace:
+		query = rawQuery
+	case QueryPolicyMerge:
+		query = mergeQueries(targetQuery, rawQuery)
+	default:
+		query = joinQueries(targetQuery, rawQuery)
+	}
+
+	if query != "" {
+		target += "?" + query
+	}
+	if hasFragment {
+		target += "#" + fragment
+	}
+
+	return target
+}
+
+// mergeQueries combines the parameters of two raw query strings, with parameters in the second replacing those
+// of the same name in the first. If either cannot be parsed, the queries are joined as they are.
+func mergeQueries(base, override string) string {
+	baseValues, err := url.ParseQuery(base)
+	if err != nil {
+		return joinQueries(base, override)
+	}
+
+	overrideValues, err := url.ParseQuery(override)
+	if err != nil {
+		return joinQueries(base, override)
+	}
+
+	for name, values := range overrideValues {
+		baseValues[name] = values
+	}
+
+	return baseValues.Encode()
+}
+
+// joinQueries appends one raw query string to another
+func joinQueries(first, second string) string {
+	if first == "" || second == "" {
+		return first + second
+	}
+
+	return first + "&" + second
+}
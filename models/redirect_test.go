@@ -0,0 +1,356 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestActivationStatus(t *testing.T) {
+	now := time.Date(2025, time.June, 11, 7, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	Convey("Given a redirect without a validity window", t, func() {
+		redirect := Redirect{From: "/old", To: "/new"}
+
+		Convey("Then it is active", func() {
+			So(redirect.ActivationStatus(now), ShouldEqual, RedirectStatusActive)
+			So(redirect.IsActive(now), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a redirect that starts in the future", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", ValidFrom: &after}
+
+		Convey("Then it is scheduled", func() {
+			So(redirect.ActivationStatus(now), ShouldEqual, RedirectStatusScheduled)
+			So(redirect.IsActive(now), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a redirect that starts exactly now", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", ValidFrom: &now, ValidUntil: &after}
+
+		Convey("Then it is active", func() {
+			So(redirect.ActivationStatus(now), ShouldEqual, RedirectStatusActive)
+		})
+	})
+
+	Convey("Given a redirect whose window has finished", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", ValidFrom: &before, ValidUntil: &now}
+
+		Convey("Then it has ended", func() {
+			So(redirect.ActivationStatus(now), ShouldEqual, RedirectStatusEnded)
+			So(redirect.IsActive(now), ShouldBeFalse)
+		})
+	})
+}
+
+func TestIsPermanent(t *testing.T) {
+	later := time.Date(2025, time.June, 11, 7, 0, 0, 0, time.UTC)
+
+	Convey("Given a redirect with a permanent status code and no time limits", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", StatusCode: http.StatusPermanentRedirect}
+
+		Convey("Then it is permanent", func() {
+			So(redirect.IsPermanent(), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a redirect with a temporary status code", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", StatusCode: http.StatusFound}
+
+		Convey("Then it is not permanent", func() {
+			So(redirect.IsPermanent(), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given redirects that expire or have a validity window", t, func() {
+		redirects := []Redirect{
+			{From: "/old", To: "/new", ExpiresAt: &later},
+			{From: "/old", To: "/new", ValidFrom: &later},
+			{From: "/old", To: "/new", ValidUntil: &later},
+		}
+
+		Convey("Then none of them are permanent", func() {
+			for _, redirect := range redirects {
+				So(redirect.IsPermanent(), ShouldBeFalse)
+			}
+		})
+	})
+}
+
+func TestRemainingTTL(t *testing.T) {
+	now := time.Date(2025, time.June, 11, 7, 0, 0, 0, time.UTC)
+
+	Convey("Given a redirect that does not expire", t, func() {
+		redirect := Redirect{From: "/old", To: "/new"}
+
+		Convey("Then the remaining ttl is zero", func() {
+			So(redirect.RemainingTTL(now), ShouldEqual, 0)
+		})
+	})
+
+	Convey("Given a redirect that expires in part of a second over a minute", t, func() {
+		expiresAt := now.Add(time.Minute + time.Millisecond)
+		redirect := Redirect{From: "/old", To: "/new", ExpiresAt: &expiresAt}
+
+		Convey("Then the remaining ttl is rounded up", func() {
+			So(redirect.RemainingTTL(now), ShouldEqual, 61)
+		})
+	})
+
+	Convey("Given a redirect that has already expired", t, func() {
+		expiresAt := now.Add(-time.Second)
+		redirect := Redirect{From: "/old", To: "/new", ExpiresAt: &expiresAt}
+
+		Convey("Then the remaining ttl is zero", func() {
+			So(redirect.RemainingTTL(now), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestTargetFor(t *testing.T) {
+	Convey("Given an exact redirect", t, func() {
+		redirect := Redirect{From: "/old", To: "/new", Type: RedirectTypeExact}
+
+		Convey("Then the target is the 'to' path", func() {
+			So(redirect.TargetFor("/old"), ShouldEqual, "/new")
+		})
+	})
+
+	Convey("Given a prefix redirect that keeps the rest of the path", t, func() {
+		redirect := Redirect{From: "/old/section/*", To: "/new/section/*", Type: RedirectTypePrefix}
+
+		Convey("Then the rest of the requested path is appended to the target", func() {
+			So(redirect.TargetFor("/old/section/page/data"), ShouldEqual, "/new/section/page/data")
+		})
+
+		Convey("Then the root of the section is redirected to the root of the target", func() {
+			So(redirect.TargetFor("/old/section"), ShouldEqual, "/new/section")
+		})
+	})
+
+	Convey("Given a prefix redirect that sends the whole section to one page", t, func() {
+		redirect := Redirect{From: "/old/section/*", To: "/new/landing-page", Type: RedirectTypePrefix}
+
+		Convey("Then the target is the 'to' path", func() {
+			So(redirect.TargetFor("/old/section/page"), ShouldEqual, "/new/landing-page")
+		})
+	})
+}
+
+func TestTargetForRegex(t *testing.T) {
+	Convey("Given a regex redirect", t, func() {
+		redirect := Redirect{From: `^/datasets/(\w+)/editions/(\d{4})$`, To: "/datasets/$1/editions/$2/latest", Type: RedirectTypeRegex}
+
+		Convey("Then it matches paths that match its pattern", func() {
+			So(redirect.MatchesPath("/datasets/cpih01/editions/2024"), ShouldBeTrue)
+			So(redirect.MatchesPath("/datasets/cpih01/editions/latest"), ShouldBeFalse)
+		})
+
+		Convey("Then the captured groups are substituted into the target", func() {
+			So(redirect.TargetFor("/datasets/cpih01/editions/2024"), ShouldEqual, "/datasets/cpih01/editions/2024/latest")
+		})
+	})
+
+	Convey("Given a regex redirect whose target path starts with a captured group", t, func() {
+		redirect := Redirect{From: `^/legacy/(.*)$`, To: "/$1", Type: RedirectTypeRegex}
+
+		Convey("Then the target cannot be made to leave the site", func() {
+			So(redirect.TargetFor("/legacy//example.com"), ShouldEqual, "/example.com")
+		})
+	})
+
+	Convey("Given a regex redirect to an external URL", t, func() {
+		redirect := Redirect{From: `^/archive/(.*)$`, To: "https://webarchive.nationalarchives.gov.uk/ons/$1", Type: RedirectTypeRegex}
+
+		Convey("Then the captured groups are substituted into the external URL", func() {
+			So(redirect.TargetFor("/archive/economy/gdp"), ShouldEqual, "https://webarchive.nationalarchives.gov.uk/ons/economy/gdp")
+		})
+	})
+
+	Convey("Given an exact redirect", t, func() {
+		redirect := Redirect{From: "/datasets", To: "/data", Type: RedirectTypeExact}
+
+		Convey("Then it does not match paths as a pattern", func() {
+			So(redirect.MatchesPath("/datasets"), ShouldBeFalse)
+		})
+	})
+}
+
+func TestRedirectKey(t *testing.T) {
+	Convey("Given a global redirect", t, func() {
+		redirect := Redirect{From: "/economy"}
+
+		Convey("Then its key is its 'from' path", func() {
+			So(redirect.Key(), ShouldEqual, "/economy")
+		})
+
+		Convey("And the key can be parsed back into its host and 'from' path", func() {
+			host, from := ParseRedirectKey(redirect.Key())
+			So(host, ShouldBeEmpty)
+			So(from, ShouldEqual, "/economy")
+		})
+	})
+
+	Convey("Given a host scoped redirect", t, func() {
+		redirect := Redirect{Host: "cy.ons.gov.uk", From: "/economy"}
+
+		Convey("Then its key includes the host", func() {
+			So(redirect.Key(), ShouldEqual, "//cy.ons.gov.uk/economy")
+		})
+
+		Convey("And the key can be parsed back into its host and 'from' path", func() {
+			host, from := ParseRedirectKey(redirect.Key())
+			So(host, ShouldEqual, "cy.ons.gov.uk")
+			So(from, ShouldEqual, "/economy")
+		})
+	})
+
+	Convey("Given a host scoped regex redirect", t, func() {
+		redirect := Redirect{Host: "cy.ons.gov.uk:8080", From: `^/datasets/(\w+)$`}
+
+		Convey("Then the key can be parsed back into its host and 'from' pattern", func() {
+			host, from := ParseRedirectKey(redirect.Key())
+			So(host, ShouldEqual, "cy.ons.gov.uk:8080")
+			So(from, ShouldEqual, `^/datasets/(\w+)$`)
+		})
+	})
+}
+
+func TestRedirectID(t *testing.T) {
+	Convey("Given a key that uses the URL safe base64 characters when encoded", t, func() {
+		key := "/~~~"
+
+		Convey("Then its id is URL safe base64 with padding", func() {
+			So(RedirectID(key), ShouldEqual, "L35-fg==")
+		})
+
+		Convey("And the id can be parsed back into the key with or without padding", func() {
+			parsed, err := ParseRedirectID("L35-fg==")
+			So(err, ShouldBeNil)
+			So(parsed, ShouldEqual, key)
+
+			parsed, err = ParseRedirectID("L35-fg")
+			So(err, ShouldBeNil)
+			So(parsed, ShouldEqual, key)
+		})
+	})
+
+	Convey("Given ids that are not URL safe base64", t, func() {
+		Convey("Then they cannot be parsed", func() {
+			for _, id := range []string{"L35+fg==", "L2Vjb25vbXkvb2xkLXBhdGgg==", "not*base64"} {
+				_, err := ParseRedirectID(id)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
+
+func TestIsValidHost(t *testing.T) {
+	Convey("Given a list of hosts", t, func() {
+		Convey("Then lowercase hostnames with an optional port are valid", func() {
+			So(IsValidHost("www.ons.gov.uk"), ShouldBeTrue)
+			So(IsValidHost("cy.ons.gov.uk"), ShouldBeTrue)
+			So(IsValidHost("localhost:20000"), ShouldBeTrue)
+		})
+
+		Convey("Then anything else is invalid", func() {
+			So(IsValidHost("WWW.ONS.GOV.UK"), ShouldBeFalse)
+			So(IsValidHost("www.ons.gov.uk/economy"), ShouldBeFalse)
+			So(IsValidHost("-ons.gov.uk"), ShouldBeFalse)
+			So(IsValidHost("ons.gov.uk*"), ShouldBeFalse)
+		})
+	})
+}
+
+func TestResolveTarget(t *testing.T) {
+	Convey("Given a redirect to a target with its own query string", t, func() {
+		redirect := Redirect{From: "/old", To: "/new?edition=2024&format=csv#data", Type: RedirectTypeExact}
+
+		Convey("When the query policy is preserve", func() {
+			redirect.QueryPolicy = QueryPolicyPreserve
+
+			Convey("Then the query of the request is appended to the query of the target", func() {
+				So(redirect.ResolveTarget("/old", "format=xlsx&page=2"), ShouldEqual, "/new?edition=2024&format=csv&format=xlsx&page=2#data")
+			})
+		})
+
+		Convey("When the query policy is drop", func() {
+			redirect.QueryPolicy = QueryPolicyDrop
+
+			Convey("Then only the query of the target is kept", func() {
+				So(redirect.ResolveTarget("/old", "format=xlsx&page=2"), ShouldEqual, "/new?edition=2024&format=csv#data")
+			})
+		})
+
+		Convey("When the query policy is merge", func() {
+			redirect.QueryPolicy = QueryPolicyMerge
+
+			Convey("Then parameters of the request replace those of the same name in the target", func() {
+				So(redirect.ResolveTarget("/old", "format=xlsx&page=2"), ShouldEqual, "/new?edition=2024&format=xlsx&page=2#data")
+			})
+		})
+
+		Convey("When the query policy is replace", func() {
+			redirect.QueryPolicy = QueryPolicyReplace
+
+			Convey("Then the query of the request is used instead of the query of the target", func() {
+				So(redirect.ResolveTarget("/old", "format=xlsx&page=2"), ShouldEqual, "/new?format=xlsx&page=2#data")
+			})
+		})
+
+		Convey("When the request has no query string", func() {
+			redirect.QueryPolicy = QueryPolicyReplace
+
+			Convey("Then a replace policy removes the query of the target", func() {
+				So(redirect.ResolveTarget("/old", ""), ShouldEqual, "/new#data")
+			})
+		})
+	})
+
+	Convey("Given a prefix redirect without a query policy", t, func() {
+		redirect := Redirect{From: "/old/*", To: "/new/*", Type: RedirectTypePrefix}
+
+		Convey("Then the query of the request is preserved on the target", func() {
+			So(redirect.ResolveTarget("/old/page", "a=1"), ShouldEqual, "/new/page?a=1")
+		})
+	})
+}
+
+func TestETag(t *testing.T) {
+	updatedAt := time.Date(2025, time.June, 11, 7, 0, 0, 0, time.UTC)
+
+	Convey("Given a stored redirect", t, func() {
+		redirect := Redirect{From: "/economy", To: "/business", UpdatedAt: &updatedAt}
+		etag := redirect.ETag()
+
+		Convey("Then its ETag is a quoted strong entity tag", func() {
+			So(etag, ShouldStartWith, `"`)
+			So(etag, ShouldEndWith, `"`)
+			So(etag, ShouldHaveLength, 34)
+		})
+
+		Convey("Then its ETag does not change with the fields worked out when it is returned", func() {
+			returned := redirect
+			returned.ID = "L2Vjb25vbXk="
+			returned.Links = RedirectLinks{Self: RedirectSelf{Href: "http://localhost/v1/redirects/L2Vjb25vbXk=", ID: "L2Vjb25vbXk="}}
+			returned.TTL = 3600
+			returned.Status = RedirectStatusActive
+
+			So(returned.ETag(), ShouldEqual, etag)
+		})
+
+		Convey("Then its ETag changes when it is saved again", func() {
+			saved := redirect
+			savedAt := updatedAt.Add(time.Second)
+			saved.UpdatedAt = &savedAt
+
+			So(saved.ETag(), ShouldNotEqual, etag)
+		})
+	})
+}
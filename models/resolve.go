@@ -0,0 +1,46 @@
+package models
+
+import "net/http"
+
+// ResolvedRedirect represents response body when resolving where a requested path ends up after following
+// every redirect that applies to it
+type ResolvedRedirect struct {
+	Path       string        `json:"path"`
+	Target     string        `json:"target"`
+	StatusCode int           `json:"status_code"`
+	Hops       []RedirectHop `json:"hops"`
+}
+
+// RedirectHop is a single redirect followed while resolving a path
+type RedirectHop struct {
+	Path       string        `json:"path"`
+	Target     string        `json:"target"`
+	StatusCode int           `json:"status_code"`
+	Links      RedirectLinks `json:"links"`
+}
+
+// ChainStatusCode returns the status code to use when a visitor is sent straight to the end of a chain of
+// redirects with the given status codes. The result is only permanent if every redirect in the chain is
+// permanent, and only preserves the request method if every redirect in the chain does.
+func ChainStatusCode(codes []int) int {
+	permanent, preservesMethod := true, true
+	for _, code := range codes {
+		if code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			permanent = false
+		}
+		if code != http.StatusTemporaryRedirect && code != http.StatusPermanentRedirect {
+			preservesMethod = false
+		}
+	}
+
+	switch {
+	case permanent && preservesMethod:
+		return http.StatusPermanentRedirect
+	case permanent:
+		return http.StatusMovedPermanently
+	case preservesMethod:
+		return http.StatusTemporaryRedirect
+	default:
+		return http.StatusFound
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestChainStatusCode(t *testing.T) {
+	Convey("Given chains of redirects with different status codes", t, func() {
+		Convey("Then a chain of permanent redirects is permanent", func() {
+			So(ChainStatusCode([]int{http.StatusMovedPermanently, http.StatusPermanentRedirect}), ShouldEqual, http.StatusMovedPermanently)
+			So(ChainStatusCode([]int{http.StatusPermanentRedirect}), ShouldEqual, http.StatusPermanentRedirect)
+		})
+
+		Convey("Then a chain including a temporary redirect is temporary", func() {
+			So(ChainStatusCode([]int{http.StatusMovedPermanently, http.StatusFound}), ShouldEqual, http.StatusFound)
+			So(ChainStatusCode([]int{http.StatusPermanentRedirect, http.StatusTemporaryRedirect}), ShouldEqual, http.StatusTemporaryRedirect)
+		})
+
+		Convey("Then the request method is only preserved if every redirect preserves it", func() {
+			So(ChainStatusCode([]int{http.StatusPermanentRedirect, http.StatusMovedPermanently}), ShouldEqual, http.StatusMovedPermanently)
+			So(ChainStatusCode([]int{http.StatusTemporaryRedirect, http.StatusFound}), ShouldEqual, http.StatusFound)
+		})
+	})
+}
@@ -3,12 +3,14 @@ package sdk
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 
 	"github.com/ONSdigital/dis-redirect-api/api"
+	"github.com/ONSdigital/dis-redirect-api/models"
 	apiError "github.com/ONSdigital/dis-redirect-api/sdk/go/errors"
 	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
 	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
@@ -96,6 +98,16 @@ func (cli *Client) callRedirectAPI(ctx context.Context, path, method string, hea
 	q := req.URL.Query()
 	q.Add(api.QueryParameterCount, queryParams.Get(api.QueryParameterCount))
 	q.Add(api.QueryParameterCursor, queryParams.Get(api.QueryParameterCursor))
+
+	// add any other query parameters, such as filters, as they are
+	for name, values := range queryParams {
+		if name == api.QueryParameterCount || name == api.QueryParameterCursor {
+			continue
+		}
+		for _, value := range values {
+			q.Add(name, value)
+		}
+	}
 	req.URL.RawQuery = q.Encode()
 
 	resp, err := cli.hcCli.Client.Do(ctx, req)
@@ -115,10 +127,7 @@ func (cli *Client) callRedirectAPI(ctx context.Context, path, method string, hea
 	}
 
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= 400 {
-		return respInfo, apiError.StatusError{
-			Err:  fmt.Errorf("failed as unexpected code from redirect api: %v", resp.StatusCode),
-			Code: resp.StatusCode,
-		}
+		return respInfo, responseError(resp)
 	}
 
 	if resp.Body == nil {
@@ -135,6 +144,32 @@ func (cli *Client) callRedirectAPI(ctx context.Context, path, method string, hea
 	return respInfo, nil
 }
 
+// responseError returns the error given in the body of an unsuccessful response from the redirect API, or a
+// StatusError if the body does not describe the error
+func responseError(resp *http.Response) apiError.Error {
+	statusErr := apiError.StatusError{
+		Err:  fmt.Errorf("failed as unexpected code from redirect api: %v", resp.StatusCode),
+		Code: resp.StatusCode,
+	}
+
+	if resp.Body == nil {
+		return statusErr
+	}
+
+	var errorResponse models.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err != nil || errorResponse.Code == "" {
+		return statusErr
+	}
+
+	return apiError.ResponseError{
+		StatusCode: resp.StatusCode,
+		Code:       errorResponse.Code,
+		Message:    errorResponse.Message,
+		RequestID:  errorResponse.RequestID,
+		Existing:   errorResponse.Existing,
+	}
+}
+
 // closeResponseBody closes the response body and logs an error if unsuccessful
 func closeResponseBody(resp *http.Response) apiError.Error {
 	if resp.Body != nil {
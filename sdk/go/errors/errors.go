@@ -1,6 +1,10 @@
 package errors
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+)
 
 // Error represents a handler error. It provides methods for a HTTP status
 // code and embeds the built-in error interface.
@@ -48,3 +52,79 @@ func ErrorMessage(err error) string {
 
 	return err.Error()
 }
+
+// ResponseError represents an error returned by the redirect API, with the code identifying the error, the message
+// describing it and the ID of the request that failed. Existing is the redirect that already exists when a request
+// to only create a redirect fails with ErrRedirectExists.
+type ResponseError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+	Existing   *models.Redirect
+}
+
+// Errors returned by the redirect API that callers commonly handle, to compare with using errors.Is
+var (
+	ErrNotFound           = ResponseError{Code: models.ErrorCodeNotFound}
+	ErrPreconditionFailed = ResponseError{Code: models.ErrorCodePreconditionFailed}
+	ErrRedirectExists     = ResponseError{Code: models.ErrorCodeRedirectExists}
+	ErrWriteConflict      = ResponseError{Code: models.ErrorCodeWriteConflict}
+)
+
+// Allows ResponseError to satisfy the error interface.
+func (e ResponseError) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+
+	return e.Message
+}
+
+// Status returns the HTTP status code.
+func (e ResponseError) Status() int {
+	return e.StatusCode
+}
+
+// Is returns true if the target is a ResponseError with the same code, so errors can be compared by code alone.
+func (e ResponseError) Is(target error) bool {
+	var t ResponseError
+	if !errors.As(target, &t) {
+		return false
+	}
+
+	return t.Code != "" && t.Code == e.Code
+}
+
+// ErrorCode returns the code identifying an error returned by the redirect API, or an empty string if the error
+// was not returned by the API with a code.
+func ErrorCode(err error) string {
+	var rerr ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.Code
+	}
+
+	return ""
+}
+
+// ErrorRequestID returns the ID of the request that failed with an error returned by the redirect API, or an
+// empty string if it is not known.
+func ErrorRequestID(err error) string {
+	var rerr ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.RequestID
+	}
+
+	return ""
+}
+
+// ErrorExistingRedirect returns the redirect that already exists when a request to only create a redirect failed
+// with ErrRedirectExists, or nil if it was not returned by the API.
+func ErrorExistingRedirect(err error) *models.Redirect {
+	var rerr ResponseError
+	if errors.As(err, &rerr) {
+		return rerr.Existing
+	}
+
+	return nil
+}
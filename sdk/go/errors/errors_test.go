@@ -2,8 +2,10 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/ONSdigital/dis-redirect-api/models"
 	c "github.com/smartystreets/goconvey/convey"
 )
 
@@ -52,4 +54,45 @@ func Test(t *testing.T) {
 			})
 		})
 	})
+
+	c.Convey("given a response error", t, func() {
+		rErr := ResponseError{
+			StatusCode: 409,
+			Code:       models.ErrorCodeWriteConflict,
+			Message:    testError,
+			RequestID:  "request-id",
+		}
+
+		c.Convey("when calling the Error and Status methods on response error", func() {
+			c.Convey("then the message and status are returned", func() {
+				c.So(rErr.Error(), c.ShouldEqual, testError)
+				c.So(rErr.Status(), c.ShouldEqual, 409)
+			})
+		})
+
+		c.Convey("when passing a wrapped response error into the helper funcs", func() {
+			err := fmt.Errorf("failed to put redirect: %w", rErr)
+
+			c.Convey("then the status, code and request id are returned", func() {
+				c.So(ErrorStatus(err), c.ShouldEqual, 409)
+				c.So(ErrorCode(err), c.ShouldEqual, models.ErrorCodeWriteConflict)
+				c.So(ErrorRequestID(err), c.ShouldEqual, "request-id")
+			})
+
+			c.Convey("then it matches errors with the same code only", func() {
+				c.So(errors.Is(err, ErrWriteConflict), c.ShouldBeTrue)
+				c.So(errors.Is(err, ErrNotFound), c.ShouldBeFalse)
+				c.So(errors.Is(err, ResponseError{}), c.ShouldBeFalse)
+			})
+		})
+
+		c.Convey("when passing a status error into the helper funcs", func() {
+			sErr := StatusError{Code: 500, Err: errors.New(testError)}
+
+			c.Convey("then no code or request id is returned", func() {
+				c.So(ErrorCode(sErr), c.ShouldBeEmpty)
+				c.So(ErrorRequestID(sErr), c.ShouldBeEmpty)
+			})
+		})
+	})
 }
@@ -10,12 +10,19 @@ import (
 const (
 	// List of available headers
 	Authorization string = request.AuthHeaderKey
+	IfMatch       string = "If-Match"
+	IfNoneMatch   string = "If-None-Match"
+
+	// ETag is the response header holding the entity tag of a redirect
+	ETag string = "ETag"
 )
 
 // Options is a struct containing for customised options for the API client
 type Options struct {
 	Headers http.Header
 	Query   url.Values
+	// CreateOnly makes PutRedirect only create a redirect, failing with a 412 status if it already exists
+	CreateOnly bool
 }
 
 func setHeaders(req *http.Request, headers http.Header) {
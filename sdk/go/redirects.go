@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
+	"github.com/ONSdigital/dis-redirect-api/api"
 	"github.com/ONSdigital/dis-redirect-api/models"
 	apiError "github.com/ONSdigital/dis-redirect-api/sdk/go/errors"
 )
@@ -13,13 +15,47 @@ import (
 const (
 	RedirectEndpoint  = "%s/v1/redirects/%s"
 	RedirectsEndpoint = "%s/v1/redirects"
+	ResolveEndpoint   = "%s/v1/resolve"
 )
 
 // GetRedirect gets the /redirects/{id} endpoint
 func (cli *Client) GetRedirect(ctx context.Context, options Options, key string) (*models.Redirect, apiError.Error) {
+	redirect, _, apiErr := cli.GetRedirectWithETag(ctx, options, key)
+	return redirect, apiErr
+}
+
+// GetRedirectWithETag gets the /redirects/{id} endpoint along with the ETag of the redirect, which can be sent in
+// an If-Match header to only change the redirect if it has not changed since. When an If-None-Match header is
+// given in the options and the redirect has not changed, the redirect returned is nil.
+func (cli *Client) GetRedirectWithETag(ctx context.Context, options Options, key string) (*models.Redirect, string, apiError.Error) {
 	path := fmt.Sprintf(RedirectEndpoint, cli.hcCli.URL, key)
 
 	respInfo, apiErr := cli.callRedirectAPI(ctx, path, http.MethodGet, options.Headers, options.Query, nil)
+	if apiErr != nil {
+		return nil, "", apiErr
+	}
+
+	etag := respInfo.Headers.Get(ETag)
+	if respInfo.Status == http.StatusNotModified {
+		return nil, etag, nil
+	}
+
+	var response models.Redirect
+	if err := json.Unmarshal(respInfo.Body, &response); err != nil {
+		return nil, "", apiError.StatusError{
+			Err: fmt.Errorf("failed to unmarshal redirect response - error is: %v", err),
+		}
+	}
+
+	return &response, etag, nil
+}
+
+// GetRedirectByPath gets the redirect from the given path via the /redirects endpoint, without needing its id. The
+// host is only given for redirects scoped to a host, and is empty for global redirects.
+func (cli *Client) GetRedirectByPath(ctx context.Context, options Options, host, from string) (*models.Redirect, apiError.Error) {
+	path := fmt.Sprintf(RedirectsEndpoint, cli.hcCli.URL)
+
+	respInfo, apiErr := cli.callRedirectAPI(ctx, path, http.MethodGet, options.Headers, redirectPathQuery(options.Query, host, from), nil)
 	if apiErr != nil {
 		return nil, apiErr
 	}
@@ -53,28 +89,146 @@ func (cli *Client) GetRedirects(ctx context.Context, options Options) (*models.R
 	return &response, nil
 }
 
-// PutRedirect updates a redirect via the /redirects/{id} endpoint
+// GetRedirectsTo gets every redirect that lands on the given target from the /redirects endpoint
+func (cli *Client) GetRedirectsTo(ctx context.Context, options Options, to string) (*models.Redirects, apiError.Error) {
+	query := url.Values{}
+	for name, values := range options.Query {
+		query[name] = values
+	}
+	query.Set("to", to)
+	options.Query = query
+
+	return cli.GetRedirects(ctx, options)
+}
+
+// Resolve gets where the given path ends up after following every redirect that applies to it, from the
+// /resolve endpoint
+func (cli *Client) Resolve(ctx context.Context, options Options, path string) (*models.ResolvedRedirect, apiError.Error) {
+	query := url.Values{}
+	for name, values := range options.Query {
+		query[name] = values
+	}
+	query.Set("path", path)
+
+	respInfo, apiErr := cli.callRedirectAPI(ctx, fmt.Sprintf(ResolveEndpoint, cli.hcCli.URL), http.MethodGet, options.Headers, query, nil)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var response models.ResolvedRedirect
+	if err := json.Unmarshal(respInfo.Body, &response); err != nil {
+		return nil, apiError.StatusError{
+			Err: fmt.Errorf("failed to unmarshal resolved redirect response - error is: %v", err),
+		}
+	}
+
+	return &response, nil
+}
+
+// CreateRedirect creates a redirect via the /redirects endpoint and returns the created redirect. It fails with a
+// 409 status if a redirect from the same path already exists.
+func (cli *Client) CreateRedirect(
+	ctx context.Context,
+	options Options,
+	payload models.Redirect,
+) (*models.Redirect, apiError.Error) {
+	path := fmt.Sprintf(RedirectsEndpoint, cli.hcCli.URL)
+
+	bodyBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, apiError.StatusError{
+			Err: fmt.Errorf("failed to marshal redirect payload - error is: %v", err),
+		}
+	}
+
+	respInfo, apiErr := cli.callRedirectAPI(ctx, path, http.MethodPost, options.Headers, options.Query, bodyBytes)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var response models.Redirect
+	if err := json.Unmarshal(respInfo.Body, &response); err != nil {
+		return nil, apiError.StatusError{
+			Err: fmt.Errorf("failed to unmarshal redirect response - error is: %v", err),
+		}
+	}
+
+	return &response, nil
+}
+
+// PutRedirect updates a redirect via the /redirects/{id} endpoint, or only creates it when options.CreateOnly is set,
+// and returns the stored redirect along with whether it was created, including when collapse_chains is set in the
+// query
 func (cli *Client) PutRedirect(
 	ctx context.Context,
 	options Options,
 	id string,
 	payload models.Redirect,
-) apiError.Error {
+) (*models.Redirect, bool, apiError.Error) {
 	path := fmt.Sprintf(RedirectEndpoint, cli.hcCli.URL, id)
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
-		return apiError.StatusError{
+		return nil, false, apiError.StatusError{
 			Err: fmt.Errorf("failed to marshal redirect payload - error is: %v", err),
 		}
 	}
 
-	_, apiErr := cli.callRedirectAPI(ctx, path, http.MethodPut, options.Headers, options.Query, bodyBytes)
+	headers := options.Headers
+	if options.CreateOnly {
+		headers = options.Headers.Clone()
+		if headers == nil {
+			headers = http.Header{}
+		}
+		headers.Set(IfNoneMatch, "*")
+	}
+
+	respInfo, apiErr := cli.callRedirectAPI(ctx, path, http.MethodPut, headers, options.Query, bodyBytes)
 	if apiErr != nil {
-		return apiErr
+		return nil, false, apiErr
 	}
 
-	return nil
+	created := respInfo.Status == http.StatusCreated
+	var response models.Redirect
+	if err := json.Unmarshal(respInfo.Body, &response); err != nil {
+		return nil, created, apiError.StatusError{
+			Err: fmt.Errorf("failed to unmarshal redirect response - error is: %v", err),
+		}
+	}
+
+	return &response, created, nil
+}
+
+// PatchRedirect partially updates a redirect via the /redirects/{id} endpoint with a JSON merge patch, where a
+// field set to nil is removed, and returns the updated redirect
+func (cli *Client) PatchRedirect(
+	ctx context.Context,
+	options Options,
+	id string,
+	patch map[string]interface{},
+) (*models.Redirect, apiError.Error) {
+	path := fmt.Sprintf(RedirectEndpoint, cli.hcCli.URL, id)
+
+	bodyBytes, err := json.Marshal(patch)
+	if err != nil {
+		return nil, apiError.StatusError{
+			Err: fmt.Errorf("failed to marshal redirect patch - error is: %v", err),
+		}
+	}
+
+	respInfo, apiErr := cli.callRedirectAPI(ctx, path, http.MethodPatch, options.Headers, options.Query, bodyBytes)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var response models.Redirect
+	if err := json.Unmarshal(respInfo.Body, &response); err != nil {
+		return nil, apiError.StatusError{
+			Err: fmt.Errorf("failed to unmarshal redirect response - error is: %v", err),
+		}
+	}
+
+	return &response, nil
 }
 
 // DeleteRedirect deletes a redirect via the /redirects/{id} endpoint
@@ -92,3 +246,34 @@ func (cli *Client) DeleteRedirect(
 
 	return nil
 }
+
+// DeleteRedirectByPath deletes the redirect from the given path via the /redirects endpoint, without needing its id.
+// The host is only given for redirects scoped to a host, and is empty for global redirects.
+func (cli *Client) DeleteRedirectByPath(
+	ctx context.Context,
+	options Options,
+	host, from string,
+) apiError.Error {
+	path := fmt.Sprintf(RedirectsEndpoint, cli.hcCli.URL)
+
+	_, apiErr := cli.callRedirectAPI(ctx, path, http.MethodDelete, options.Headers, redirectPathQuery(options.Query, host, from), nil)
+	if apiErr != nil {
+		return apiErr
+	}
+
+	return nil
+}
+
+// redirectPathQuery returns a copy of the given query parameters with the path and host of a redirect added
+func redirectPathQuery(query url.Values, host, from string) url.Values {
+	pathQuery := url.Values{}
+	for name, values := range query {
+		pathQuery[name] = values
+	}
+	pathQuery.Set(api.QueryParameterPath, from)
+	if host != "" {
+		pathQuery.Set(api.QueryParameterHost, host)
+	}
+
+	return pathQuery
+}
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/ONSdigital/dis-redirect-api/models"
+	apiError "github.com/ONSdigital/dis-redirect-api/sdk/go/errors"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -60,6 +61,157 @@ func TestGetRedirect(t *testing.T) {
 			})
 		})
 	})
+
+	Convey("Given a redirect that does not exist", t, func() {
+		body, err := json.Marshal(models.ErrorResponse{Code: models.ErrorCodeNotFound, Message: "not found", RequestID: "request-id"})
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirect is called", func() {
+			resp, err := redirectAPIClient.GetRedirect(ctx, Options{Headers: headers}, existingBase64Key)
+
+			Convey("Then the error in the response body is returned", func() {
+				So(resp, ShouldBeNil)
+				So(errors.Is(err, apiError.ErrNotFound), ShouldBeTrue)
+				So(apiError.ErrorStatus(err), ShouldEqual, http.StatusNotFound)
+				So(apiError.ErrorCode(err), ShouldEqual, models.ErrorCodeNotFound)
+				So(apiError.ErrorMessage(err), ShouldEqual, "not found")
+				So(apiError.ErrorRequestID(err), ShouldEqual, "request-id")
+			})
+		})
+	})
+
+	Convey("Given an error response without a JSON body", t, func() {
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusBadGateway,
+				Body:       io.NopCloser(bytes.NewReader([]byte("bad gateway"))),
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirect is called", func() {
+			_, err := redirectAPIClient.GetRedirect(ctx, Options{Headers: headers}, existingBase64Key)
+
+			Convey("Then a status error is returned with no code", func() {
+				So(apiError.ErrorStatus(err), ShouldEqual, http.StatusBadGateway)
+				So(apiError.ErrorCode(err), ShouldBeEmpty)
+				So(errors.Is(err, apiError.ErrNotFound), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestGetRedirectWithETag(t *testing.T) {
+	t.Parallel()
+
+	etag := `"5d41402abc4b2a76b9719d911017c592"`
+	etagHeader := http.Header{}
+	etagHeader.Set(ETag, etag)
+
+	Convey("Given a redirect with an ETag", t, func() {
+		body, err := json.Marshal(getRedirectResponse)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     etagHeader,
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirectWithETag is called", func() {
+			resp, respETag, err := redirectAPIClient.GetRedirectWithETag(ctx, Options{}, existingBase64Key)
+
+			Convey("Then the redirect and its ETag are returned", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, getRedirectResponse)
+				So(respETag, ShouldEqual, etag)
+			})
+		})
+	})
+
+	Convey("Given a redirect that has not changed", t, func() {
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusNotModified,
+				Header:     etagHeader,
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirectWithETag is called with an If-None-Match header", func() {
+			headers := http.Header{IfNoneMatch: {etag}}
+			resp, respETag, err := redirectAPIClient.GetRedirectWithETag(ctx, Options{Headers: headers}, existingBase64Key)
+
+			Convey("Then no redirect is returned, along with its ETag", func() {
+				So(err, ShouldBeNil)
+				So(resp, ShouldBeNil)
+				So(respETag, ShouldEqual, etag)
+			})
+
+			Convey("And the If-None-Match header is sent", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Header.Get(IfNoneMatch), ShouldEqual, etag)
+			})
+		})
+	})
+}
+
+func TestGetRedirectByPath(t *testing.T) {
+	t.Parallel()
+
+	Convey("Given a redirect scoped to a host", t, func() {
+		body, err := json.Marshal(getRedirectResponse)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirectByPath is called with its host and path", func() {
+			resp, err := redirectAPIClient.GetRedirectByPath(ctx, Options{}, "cy.ons.gov.uk", "/economy/old-path")
+
+			Convey("Then the redirect is returned", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, getRedirectResponse)
+			})
+
+			Convey("And the redirect is requested by its host and path", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Method, ShouldEqual, http.MethodGet)
+				So(doCalls[0].Req.URL.Path, ShouldEqual, "/v1/redirects")
+				So(doCalls[0].Req.URL.Query().Get("path"), ShouldEqual, "/economy/old-path")
+				So(doCalls[0].Req.URL.Query().Get("host"), ShouldEqual, "cy.ons.gov.uk")
+			})
+		})
+	})
 }
 
 func TestGetRedirects(t *testing.T) {
@@ -177,38 +329,210 @@ func TestGetRedirects(t *testing.T) {
 	})
 }
 
-func TestPutRedirect(t *testing.T) {
+func TestGetRedirectsTo(t *testing.T) {
+	t.Parallel()
+
+	Convey("Given a request to get the redirects to a target", t, func() {
+		body, err := json.Marshal(getRedirectsResponse)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When GetRedirectsTo is called with a host filter", func() {
+			queryValues := url.Values{"host": {"cy.ons.gov.uk"}}
+			resp, err := redirectAPIClient.GetRedirectsTo(ctx, Options{Query: queryValues}, "/economy/new-path")
+
+			Convey("Then the expected response body is returned", func() {
+				So(*resp, ShouldResemble, getRedirectsResponse)
+				So(err, ShouldBeNil)
+
+				Convey("And client.Do should be called once with the target and the other query parameters", func() {
+					doCalls := httpClient.DoCalls()
+					So(doCalls, ShouldHaveLength, 1)
+					So(doCalls[0].Req.Method, ShouldEqual, "GET")
+					So(doCalls[0].Req.URL.Path, ShouldEqual, "/v1/redirects")
+					So(doCalls[0].Req.URL.Query().Get("to"), ShouldEqual, "/economy/new-path")
+					So(doCalls[0].Req.URL.Query().Get("host"), ShouldEqual, "cy.ons.gov.uk")
+				})
+
+				Convey("And the query parameters given are left unchanged", func() {
+					So(queryValues.Has("to"), ShouldBeFalse)
+				})
+			})
+		})
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	resolvedRedirect := models.ResolvedRedirect{
+		Path:       "/economy/old-path",
+		Target:     "/economy/new-path",
+		StatusCode: http.StatusMovedPermanently,
+		Hops: []models.RedirectHop{
+			{Path: "/economy/old-path", Target: "/economy/new-path", StatusCode: http.StatusMovedPermanently},
+		},
+	}
+
+	Convey("Given a request to resolve a path", t, func() {
+		body, err := json.Marshal(resolvedRedirect)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When Resolve is called", func() {
+			resp, err := redirectAPIClient.Resolve(ctx, Options{}, "/economy/old-path")
+
+			Convey("Then the expected response body is returned", func() {
+				So(*resp, ShouldResemble, resolvedRedirect)
+				So(err, ShouldBeNil)
+
+				Convey("And client.Do should be called once with the expected parameters", func() {
+					doCalls := httpClient.DoCalls()
+					So(doCalls, ShouldHaveLength, 1)
+					So(doCalls[0].Req.Method, ShouldEqual, "GET")
+					So(doCalls[0].Req.URL.Path, ShouldEqual, "/v1/resolve")
+					So(doCalls[0].Req.URL.Query().Get("path"), ShouldEqual, "/economy/old-path")
+				})
+			})
+		})
+	})
+}
+
+func TestCreateRedirect(t *testing.T) {
 	t.Parallel()
-	ctx := context.Background()
 
 	headers := http.Header{
 		Authorization: {AuthorizedUserToken},
 	}
+	redirect := models.Redirect{From: "/old-url", To: "/new-url"}
 
 	Convey("Given a successful 201 Created response from dis-redirect-api", t, func() {
+		stored := redirect
+		stored.ID = "L29sZC11cmw="
+		body, err := json.Marshal(stored)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
 		httpClient := newMockHTTPClient(
 			&http.Response{
 				StatusCode: http.StatusCreated,
-				Body:       nil,
-				Header:     nil,
+				Body:       io.NopCloser(bytes.NewReader(body)),
 			},
 			nil,
 		)
 
 		redirectAPIClient := newRedirectAPIClient(t, httpClient)
 
+		Convey("When CreateRedirect is called", func() {
+			resp, err := redirectAPIClient.CreateRedirect(ctx, Options{Headers: headers}, redirect)
+
+			Convey("Then the created redirect is returned", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, stored)
+			})
+
+			Convey("And the redirect is posted to the redirects endpoint", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Method, ShouldEqual, http.MethodPost)
+				So(doCalls[0].Req.URL.Path, ShouldEqual, "/v1/redirects")
+			})
+		})
+	})
+
+	Convey("Given a 409 Conflict response from dis-redirect-api as the redirect already exists", t, func() {
+		body, err := json.Marshal(models.ErrorResponse{Code: models.ErrorCodeRedirectExists, Message: "the redirect already exists"})
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusConflict,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When CreateRedirect is called", func() {
+			resp, err := redirectAPIClient.CreateRedirect(ctx, Options{Headers: headers}, redirect)
+
+			Convey("Then an error is returned saying the redirect exists", func() {
+				So(resp, ShouldBeNil)
+				So(err.Status(), ShouldEqual, http.StatusConflict)
+				So(errors.Is(err, apiError.ErrRedirectExists), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestPutRedirect(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	headers := http.Header{
+		Authorization: {AuthorizedUserToken},
+	}
+
+	Convey("Given a successful 201 Created response from dis-redirect-api", t, func() {
 		redirect := models.Redirect{
 			From: "/old-url",
 			To:   "/new-url",
 		}
 
+		stored := redirect
+		stored.ID = "L29sZC11cmw="
+		body, err := json.Marshal(stored)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+				Header:     nil,
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
 		Convey("When PutRedirect is called", func() {
-			err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L29sZC11cmw=", redirect) // base64(/old-url)
+			resp, created, err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L29sZC11cmw=", redirect) // base64(/old-url)
 
 			Convey("Then it succeeds with no errors returned", func() {
 				So(err, ShouldBeNil)
 			})
 
+			Convey("And the stored redirect is returned as created", func() {
+				So(*resp, ShouldResemble, stored)
+				So(created, ShouldBeTrue)
+			})
+
 			Convey("And client.Do should be called once with the expected URL", func() {
 				doCalls := httpClient.DoCalls()
 				So(doCalls, ShouldHaveLength, 1)
@@ -218,6 +542,44 @@ func TestPutRedirect(t *testing.T) {
 		})
 	})
 
+	Convey("Given a successful 200 OK response from dis-redirect-api", t, func() {
+		body, err := json.Marshal(getRedirectResponse)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When PutRedirect is called", func() {
+			resp, created, err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, existingBase64Key, getRedirectResponse)
+
+			Convey("Then the stored redirect is returned as updated", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, getRedirectResponse)
+				So(created, ShouldBeFalse)
+			})
+		})
+
+		Convey("When PutRedirect is called with chains collapsed", func() {
+			options := Options{Headers: headers, Query: url.Values{"collapse_chains": {"true"}}}
+			resp, created, err := redirectAPIClient.PutRedirect(ctx, options, existingBase64Key, getRedirectResponse)
+
+			Convey("Then the stored redirect is still returned", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, getRedirectResponse)
+				So(created, ShouldBeFalse)
+			})
+		})
+	})
+
 	Convey("Given a 500 Internal Server Error from dis-redirect-api", t, func() {
 		httpClient := newMockHTTPClient(
 			&http.Response{
@@ -230,7 +592,7 @@ func TestPutRedirect(t *testing.T) {
 		redirect := models.Redirect{From: "/broken", To: "/fail"}
 
 		Convey("When PutRedirect is called", func() {
-			err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L2Jyb2tlbg==", redirect)
+			_, _, err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L2Jyb2tlbg==", redirect)
 
 			Convey("Then an error is returned", func() {
 				So(err, ShouldNotBeNil)
@@ -245,6 +607,48 @@ func TestPutRedirect(t *testing.T) {
 		})
 	})
 
+	Convey("Given a 412 Precondition Failed response from dis-redirect-api as the redirect already exists", t, func() {
+		existing := &models.Redirect{From: "/old-url", To: "/existing-url", StatusCode: http.StatusMovedPermanently}
+		body, err := json.Marshal(models.ErrorResponse{Code: models.ErrorCodeRedirectExists, Message: "the redirect already exists", Existing: existing})
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusPreconditionFailed,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+		redirect := models.Redirect{From: "/old-url", To: "/new-url"}
+
+		Convey("When PutRedirect is called to only create the redirect", func() {
+			options := Options{Headers: headers, CreateOnly: true}
+			_, _, err := redirectAPIClient.PutRedirect(ctx, options, "L29sZC11cmw=", redirect)
+
+			Convey("Then an error is returned with the 412 status saying the redirect exists", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusPreconditionFailed)
+				So(errors.Is(err, apiError.ErrRedirectExists), ShouldBeTrue)
+			})
+
+			Convey("And the existing redirect is returned with the error", func() {
+				So(apiError.ErrorExistingRedirect(err), ShouldResemble, existing)
+			})
+
+			Convey("And the request only allows the redirect to be created, leaving the given headers alone", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Header.Get(IfNoneMatch), ShouldEqual, "*")
+				So(doCalls[0].Req.Header.Get(Authorization), ShouldEqual, AuthorizedUserToken)
+				So(options.Headers.Get(IfNoneMatch), ShouldBeEmpty)
+			})
+		})
+	})
+
 	Convey("Given an unexpected client error occurs", t, func() {
 		clientErr := errors.New("network error")
 		httpClient := newMockHTTPClient(nil, clientErr)
@@ -253,7 +657,7 @@ func TestPutRedirect(t *testing.T) {
 		redirect := models.Redirect{From: "/error", To: "/nowhere"}
 
 		Convey("When PutRedirect is called", func() {
-			err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L2Vycm9y", redirect)
+			_, _, err := redirectAPIClient.PutRedirect(ctx, Options{Headers: headers}, "L2Vycm9y", redirect)
 
 			Convey("Then a wrapped error is returned", func() {
 				So(err, ShouldNotBeNil)
@@ -268,6 +672,74 @@ func TestPutRedirect(t *testing.T) {
 	})
 }
 
+func TestPatchRedirect(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	headers := http.Header{
+		Authorization: {AuthorizedUserToken},
+	}
+
+	Convey("Given a successful 200 OK response from dis-redirect-api", t, func() {
+		body, err := json.Marshal(getRedirectResponse)
+		if err != nil {
+			t.Errorf("failed to setup test data, error: %v", err)
+		}
+
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(body)),
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When PatchRedirect is called", func() {
+			patch := map[string]interface{}{"status_code": http.StatusPermanentRedirect, "valid_until": nil}
+			resp, err := redirectAPIClient.PatchRedirect(ctx, Options{Headers: headers}, existingBase64Key, patch)
+
+			Convey("Then the updated redirect is returned with no errors", func() {
+				So(err, ShouldBeNil)
+				So(*resp, ShouldResemble, getRedirectResponse)
+			})
+
+			Convey("And client.Do should be called once with the merge patch", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Method, ShouldEqual, http.MethodPatch)
+				So(doCalls[0].Req.URL.Path, ShouldEqual, fmt.Sprintf("/v1/redirects/%s", existingBase64Key))
+
+				sent, err := io.ReadAll(doCalls[0].Req.Body)
+				So(err, ShouldBeNil)
+				So(string(sent), ShouldEqual, `{"status_code":308,"valid_until":null}`)
+			})
+		})
+	})
+
+	Convey("Given a 400 Bad Request response from dis-redirect-api", t, func() {
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusBadRequest,
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When PatchRedirect is called", func() {
+			resp, err := redirectAPIClient.PatchRedirect(ctx, Options{Headers: headers}, existingBase64Key, map[string]interface{}{"to": nil})
+
+			Convey("Then an error is returned", func() {
+				So(resp, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
+
 func TestDeleteRedirect(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -360,3 +832,35 @@ func TestDeleteRedirect(t *testing.T) {
 		})
 	})
 }
+
+func TestDeleteRedirectByPath(t *testing.T) {
+	t.Parallel()
+
+	Convey("Given a successful 204 No Content response from dis-redirect-api", t, func() {
+		httpClient := newMockHTTPClient(
+			&http.Response{
+				StatusCode: http.StatusNoContent,
+			},
+			nil,
+		)
+
+		redirectAPIClient := newRedirectAPIClient(t, httpClient)
+
+		Convey("When DeleteRedirectByPath is called for a global redirect", func() {
+			err := redirectAPIClient.DeleteRedirectByPath(ctx, Options{}, "", "/old-url")
+
+			Convey("Then it succeeds with no error returned", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("And the redirect is deleted by its path alone", func() {
+				doCalls := httpClient.DoCalls()
+				So(doCalls, ShouldHaveLength, 1)
+				So(doCalls[0].Req.Method, ShouldEqual, http.MethodDelete)
+				So(doCalls[0].Req.URL.Path, ShouldEqual, "/v1/redirects")
+				So(doCalls[0].Req.URL.Query().Get("path"), ShouldEqual, "/old-url")
+				So(doCalls[0].Req.URL.Query().Has("host"), ShouldBeFalse)
+			})
+		})
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"net/http"
 
+	"github.com/ONSdigital/dis-redirect-api/api"
 	"github.com/ONSdigital/dis-redirect-api/config"
 	"github.com/ONSdigital/dis-redirect-api/store"
 	disRedis "github.com/ONSdigital/dis-redis"
@@ -52,6 +53,7 @@ func (e *ExternalServiceList) GetHealthCheck(cfg *config.Config, buildTime, gitC
 func (e *Init) DoGetHTTPServer(bindAddr string, router http.Handler) HTTPServer {
 	s := dphttp.NewServer(bindAddr, router)
 	s.HandleOSSignals = false
+	s.ConnContext = api.ConnContext
 	return s
 }
 
@@ -76,7 +78,7 @@ func (e *ExternalServiceList) GetRedisClient(ctx context.Context, cfg *config.Co
 	return redis, nil
 }
 
-// DoGetRedisClient initialises a dis-redis client
+// DoGetRedisClient initialises a dis-redis client, extended with the atomic operations used by the store
 func (e *Init) DoGetRedisClient(ctx context.Context, cfg *config.Config) (store.Redis, error) {
 	clientCfg := &disRedis.ClientConfig{
 		Address:     cfg.RedisAddress,
@@ -98,13 +100,13 @@ func (e *Init) DoGetRedisClient(ctx context.Context, cfg *config.Config) (store.
 	var err error
 
 	if cfg.RedisRegion != "" && cfg.RedisService != "" && cfg.RedisClusterName != "" {
-		redisClient, err = disRedis.NewClusterClient(ctx, clientCfg)
+		redisClient, err = store.NewRedisClusterClient(ctx, clientCfg)
 		if err != nil {
 			log.Error(ctx, "failed to create dis-redis cluster client", err)
 			return nil, err
 		}
 	} else {
-		redisClient, err = disRedis.NewClient(ctx, clientCfg)
+		redisClient, err = store.NewRedisClient(ctx, clientCfg)
 		if err != nil {
 			log.Error(ctx, "failed to create dis-redis client", err)
 			return nil, err
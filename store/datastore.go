@@ -2,14 +2,39 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ONSdigital/dis-redirect-api/models"
+	disRedis "github.com/ONSdigital/dis-redis"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 )
 
 //go:generate moq -out datastoretest/redis.go -pkg storetest . Redis
 //go:generate moq -out datastoretest/datastore.go -pkg storetest . Storer
 
+// scanCount is the number of keys requested from each scan of the store when looking for redirects
+const scanCount = 1000
+
+// redirectKeyPattern matches the keys of every redirect, which always start with a '/' or the regex anchor, and
+// none of the keys of the indexes
+const redirectKeyPattern = "[/^]*"
+
+// reverseIndexKey is the key of the reverse index, a sorted set holding the key of every redirect joined to the
+// path it lands on, so that the redirects landing on a path can be looked up by range. The members are added and
+// removed one at a time by Redis, so redirects written at the same time never overwrite each other's entries.
+const reverseIndexKey = "index:to"
+
+// reverseIndexSeparator separates the target path from the redirect key in the members of the reverse index
+const reverseIndexSeparator = "\x00"
+
+// regexIndexKey is the key of the regex index, a sorted set holding the key of every regex redirect, so that the
+// regex redirects for a host can be looked up by range rather than by scanning the store
+const regexIndexKey = "index:regex"
+
 type Datastore struct {
 	Backend Storer
 }
@@ -20,7 +45,15 @@ type dataRedis interface {
 	GetKeyValuePairs(ctx context.Context, matchPattern string, count int64, cursor uint64) (keyValuePairs map[string]string, newCursor uint64, err error)
 	GetTotalKeys(ctx context.Context) (totalKeys int64, err error)
 	SetValue(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetValueIfAbsent(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	CompareAndSwapValue(ctx context.Context, key, expected string, value interface{}, expiration time.Duration) error
 	DeleteValue(ctx context.Context, key string) error
+	DeleteValueReturningPrevious(ctx context.Context, key string) (string, error)
+	CompareAndDeleteValue(ctx context.Context, key, expected string) error
+	AddToSortedSet(ctx context.Context, key string, members ...string) error
+	RemoveFromSortedSet(ctx context.Context, key string, members ...string) error
+	GetSortedSetRangeByLex(ctx context.Context, key, min, max string) ([]string, error)
+	CountExistingKeys(ctx context.Context, keys ...string) (int64, error)
 }
 
 // Redis represents all the required methods from Redis
@@ -34,22 +67,439 @@ type Storer interface {
 	dataRedis
 }
 
-func (ds *Datastore) GetRedirect(ctx context.Context, redirectID string) (string, error) {
-	return ds.Backend.GetValue(ctx, redirectID)
+// storedRedirect represents the value persisted against a redirect's 'from' key
+type storedRedirect struct {
+	To          string     `json:"to"`
+	Type        string     `json:"type,omitempty"`
+	StatusCode  int        `json:"status_code,omitempty"`
+	QueryPolicy string     `json:"query_policy,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	CreatedBy   string     `json:"created_by,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy   string     `json:"updated_by,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty"`
+	ValidUntil  *time.Time `json:"valid_until,omitempty"`
+}
+
+// GetRedirect gets the redirect stored against the given 'from' key
+func (ds *Datastore) GetRedirect(ctx context.Context, key string) (*models.Redirect, error) {
+	value, err := ds.Backend.GetValue(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeRedirect(key, value)
+}
+
+// GetRedirectWithVersion gets the redirect stored against the given 'from' key along with its version, which
+// can be given to UpdateRedirect or DeleteRedirectIfUnchanged to change the redirect only if it has not been
+// changed since
+func (ds *Datastore) GetRedirectWithVersion(ctx context.Context, key string) (redirect *models.Redirect, version string, err error) {
+	value, err := ds.Backend.GetValue(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	redirect, err = decodeRedirect(key, value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return redirect, value, nil
+}
+
+// GetRedirects gets a page of the redirects selected by the filter from the store, ordered by their key
+func (ds *Datastore) GetRedirects(ctx context.Context, filter RedirectFilter, count int64, cursor uint64) (redirects []models.Redirect, newCursor uint64, err error) {
+	keyValuePairs, newCursor, err := ds.Backend.GetKeyValuePairs(ctx, filter.matchPattern(), count, cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	redirects = make([]models.Redirect, 0, len(keyValuePairs))
+	for key, value := range keyValuePairs {
+		if !filter.Matches(key) {
+			continue
+		}
+
+		redirect, err := decodeRedirect(key, value)
+		if err != nil {
+			return nil, 0, err
+		}
+		redirects = append(redirects, *redirect)
+	}
+
+	sort.Slice(redirects, func(i, j int) bool {
+		return redirects[i].Key() < redirects[j].Key()
+	})
+
+	return redirects, newCursor, nil
+}
+
+// MatchRedirect finds the redirect that applies to the given path on the given host at the given time.
+// Redirects scoped to the host are tried before global redirects. Within each, an exact match on the path is
+// used if there is one, then the first regex redirect whose pattern matches the path, and otherwise the prefix
+// redirect with the longest matching prefix. Redirects that are outside of their validity window are ignored.
+// disRedis.ErrKeyNotFound is returned when no redirect applies.
+func (ds *Datastore) MatchRedirect(ctx context.Context, host, path string, now time.Time) (*models.Redirect, error) {
+	if host != "" {
+		redirect, err := ds.matchRedirectForHost(ctx, host, path, now)
+		if err == nil || !errors.Is(err, disRedis.ErrKeyNotFound) {
+			return redirect, err
+		}
+	}
+
+	return ds.matchRedirectForHost(ctx, "", path, now)
+}
+
+// matchRedirectForHost finds the redirect scoped to the given host that applies to the given path, or the
+// global redirect that applies when no host is given
+func (ds *Datastore) matchRedirectForHost(ctx context.Context, host, path string, now time.Time) (*models.Redirect, error) {
+	candidates := matchCandidates(path)
+	for i := range candidates {
+		candidates[i] = models.RedirectKey(host, candidates[i])
+	}
+
+	redirect, err := ds.matchKeys(ctx, candidates[:1], now)
+	if err == nil || !errors.Is(err, disRedis.ErrKeyNotFound) {
+		return redirect, err
+	}
+
+	redirect, err = ds.matchRegexRedirects(ctx, host, path, now)
+	if err == nil || !errors.Is(err, disRedis.ErrKeyNotFound) {
+		return redirect, err
+	}
+
+	return ds.matchKeys(ctx, candidates[1:], now)
+}
+
+// matchKeys returns the first active redirect stored against the given keys
+func (ds *Datastore) matchKeys(ctx context.Context, keys []string, now time.Time) (*models.Redirect, error) {
+	for _, key := range keys {
+		redirect, err := ds.GetRedirect(ctx, key)
+		if err != nil {
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if redirect.IsActive(now) {
+			return redirect, nil
+		}
+	}
+
+	return nil, disRedis.ErrKeyNotFound
+}
+
+// matchRegexRedirects returns the active regex redirect scoped to the given host whose pattern matches the
+// given path. Regex redirects are keyed by their pattern, so the keys in the regex index are matched against the
+// path before any redirect is read. When more than one pattern matches, the first in order of pattern is used so
+// that the result is predictable.
+func (ds *Datastore) matchRegexRedirects(ctx context.Context, host, path string, now time.Time) (*models.Redirect, error) {
+	keys, err := ds.Backend.GetSortedSetRangeByLex(ctx, regexIndexKey, "["+models.RedirectKey(host, "^"), "("+models.RedirectKey(host, "_"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		_, from := models.ParseRedirectKey(key)
+		pattern := models.Redirect{From: from, Type: models.RedirectTypeRegex}
+		if !pattern.MatchesPath(path) {
+			continue
+		}
+
+		redirect, err := ds.GetRedirect(ctx, key)
+		if err != nil {
+			// redirects that have expired are not removed from the regex index
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if redirect.IsActive(now) && redirect.MatchesPath(path) {
+			return redirect, nil
+		}
+	}
+
+	return nil, disRedis.ErrKeyNotFound
+}
+
+// GetRedirectsTo gets every redirect whose target lands on the given path, ordered by their key. Any query
+// string or fragment is ignored when comparing targets. The redirects are found using the reverse index, so
+// redirects written before it existed are missing until RebuildIndexes has been run.
+func (ds *Datastore) GetRedirectsTo(ctx context.Context, to string) ([]models.Redirect, error) {
+	target := models.TargetPath(to)
+
+	keys, err := ds.getReverseIndex(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	redirects := make([]models.Redirect, 0, len(keys))
+	for _, key := range keys {
+		redirect, err := ds.GetRedirect(ctx, key)
+		if err != nil {
+			// redirects that have expired are not removed from the reverse index
+			if errors.Is(err, disRedis.ErrKeyNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		if models.TargetPath(redirect.To) == target {
+			redirects = append(redirects, *redirect)
+		}
+	}
+
+	return redirects, nil
+}
+
+// CreateRedirect stores the given redirect against its 'from' key and adds it to the indexes, but only if no
+// redirect is stored against the key already. ErrValueChanged is returned if one is.
+func (ds *Datastore) CreateRedirect(ctx context.Context, redirect *models.Redirect, expiration time.Duration) error {
+	value, err := encodeRedirect(redirect)
+	if err != nil {
+		return err
+	}
+
+	key := redirect.Key()
+	created, err := ds.Backend.SetValueIfAbsent(ctx, key, value, expiration)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrValueChanged
+	}
+
+	return ds.addToIndexes(ctx, key, redirect)
+}
+
+// UpdateRedirect replaces the redirect stored against the 'from' key of the given redirect, but only if it is
+// still at the given version, and moves its key in the reverse index to its new target. ErrValueChanged is
+// returned if the stored redirect has been changed or deleted since that version was read.
+func (ds *Datastore) UpdateRedirect(ctx context.Context, redirect *models.Redirect, version string, expiration time.Duration) error {
+	value, err := encodeRedirect(redirect)
+	if err != nil {
+		return err
+	}
+
+	key := redirect.Key()
+	previous, err := decodeRedirect(key, version)
+	if err != nil {
+		return err
+	}
+
+	if err := ds.Backend.CompareAndSwapValue(ctx, key, version, value, expiration); err != nil {
+		return err
+	}
+
+	return ds.moveInIndexes(ctx, key, previous, redirect)
+}
+
+// DeleteRedirect deletes the redirect stored against the given key and removes it from the indexes.
+// disRedis.ErrKeyNotFound is returned if there is no redirect stored against the key.
+func (ds *Datastore) DeleteRedirect(ctx context.Context, key string) error {
+	value, err := ds.Backend.DeleteValueReturningPrevious(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	redirect, err := decodeRedirect(key, value)
+	if err != nil {
+		return err
+	}
+
+	return ds.removeFromIndexes(ctx, key, redirect)
+}
+
+// DeleteRedirectIfUnchanged deletes the redirect stored against the given key and removes it from the indexes,
+// but only if it is still at the given version. ErrValueChanged is returned if the stored redirect has
+// been changed or deleted since that version was read.
+func (ds *Datastore) DeleteRedirectIfUnchanged(ctx context.Context, key, version string) error {
+	redirect, err := decodeRedirect(key, version)
+	if err != nil {
+		return err
+	}
+
+	if err := ds.Backend.CompareAndDeleteValue(ctx, key, version); err != nil {
+		return err
+	}
+
+	return ds.removeFromIndexes(ctx, key, redirect)
 }
 
-func (ds *Datastore) GetRedirects(ctx context.Context, count int64, cursor uint64) (keyValuePairs map[string]string, newCursor uint64, err error) {
-	return ds.Backend.GetKeyValuePairs(ctx, "", count, cursor)
+// moveInIndexes moves the given redirect key in the reverse index from the target of the redirect it replaced,
+// if there was one, to the target of the redirect now stored, and makes sure it is in the regex index if needed
+func (ds *Datastore) moveInIndexes(ctx context.Context, key string, previous, redirect *models.Redirect) error {
+	if previous != nil && models.TargetPath(previous.To) != models.TargetPath(redirect.To) {
+		if err := ds.removeFromReverseIndex(ctx, models.TargetPath(previous.To), key); err != nil {
+			return err
+		}
+	}
+
+	return ds.addToIndexes(ctx, key, redirect)
 }
 
-func (ds *Datastore) GetTotalCount(ctx context.Context) (totalCount int, err error) {
-	var totalKeys int64
-	totalKeys, err = ds.Backend.GetTotalKeys(ctx)
+// addToIndexes records the given redirect in the reverse index and, for a regex redirect, in the regex index
+func (ds *Datastore) addToIndexes(ctx context.Context, key string, redirect *models.Redirect) error {
+	if err := ds.addToReverseIndex(ctx, models.TargetPath(redirect.To), key); err != nil {
+		return err
+	}
+
+	if redirect.Type != models.RedirectTypeRegex {
+		return nil
+	}
+
+	return ds.Backend.AddToSortedSet(ctx, regexIndexKey, key)
+}
+
+// removeFromIndexes removes the given redirect from the reverse index and, for a regex redirect, the regex index
+func (ds *Datastore) removeFromIndexes(ctx context.Context, key string, redirect *models.Redirect) error {
+	if err := ds.removeFromReverseIndex(ctx, models.TargetPath(redirect.To), key); err != nil {
+		return err
+	}
+
+	if redirect.Type != models.RedirectTypeRegex {
+		return nil
+	}
+
+	return ds.Backend.RemoveFromSortedSet(ctx, regexIndexKey, key)
+}
+
+// getReverseIndex gets the keys of the redirects recorded in the reverse index as landing on the given target
+func (ds *Datastore) getReverseIndex(ctx context.Context, target string) ([]string, error) {
+	prefix := target + reverseIndexSeparator
+	members, err := ds.Backend.GetSortedSetRangeByLex(ctx, reverseIndexKey, "["+prefix, "["+prefix+"\xff")
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(members))
+	for i, member := range members {
+		keys[i] = strings.TrimPrefix(member, prefix)
+	}
+
+	return keys, nil
+}
+
+// addToReverseIndex records the given redirect key in the reverse index as landing on the given target
+func (ds *Datastore) addToReverseIndex(ctx context.Context, target, key string) error {
+	return ds.Backend.AddToSortedSet(ctx, reverseIndexKey, target+reverseIndexSeparator+key)
+}
+
+// removeFromReverseIndex removes the given redirect key from the reverse index of the given target
+func (ds *Datastore) removeFromReverseIndex(ctx context.Context, target, key string) error {
+	return ds.Backend.RemoveFromSortedSet(ctx, reverseIndexKey, target+reverseIndexSeparator+key)
+}
+
+// RebuildIndexes adds every redirect in the store to the reverse and regex indexes, and returns the number of
+// redirects indexed. Redirects are indexed as they are written, so this only needs to be run to index the
+// redirects written before the indexes existed. It can be run while redirects are being written, as it only ever
+// adds to the indexes.
+func (ds *Datastore) RebuildIndexes(ctx context.Context) (int, error) {
+	indexed := 0
+	err := ds.WalkRedirects(ctx, RedirectFilter{}, func(redirect *models.Redirect) error {
+		if err := ds.addToIndexes(ctx, redirect.Key(), redirect); err != nil {
+			return err
+		}
+		indexed++
+		return nil
+	})
+	if err != nil {
+		return indexed, err
+	}
+
+	return indexed, nil
+}
+
+// WalkRedirects calls the given function for every redirect in the store selected by the filter, in order of
+// key within each page, scanning the store a page at a time so that the whole set of redirects is never held in
+// memory. Walking stops at the first error returned by the function.
+func (ds *Datastore) WalkRedirects(ctx context.Context, filter RedirectFilter, fn func(redirect *models.Redirect) error) error {
+	var cursor uint64
+
+	for {
+		keyValuePairs, newCursor, err := ds.Backend.GetKeyValuePairs(ctx, filter.matchPattern(), scanCount, cursor)
+		if err != nil {
+			return err
+		}
+
+		keys := make([]string, 0, len(keyValuePairs))
+		for key := range keyValuePairs {
+			if filter.Matches(key) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			redirect, err := decodeRedirect(key, keyValuePairs[key])
+			if err != nil {
+				return err
+			}
+			if err := fn(redirect); err != nil {
+				return err
+			}
+		}
+
+		if newCursor == 0 {
+			return nil
+		}
+		cursor = newCursor
+	}
+}
+
+// indexKeys are the keys of every index held in the store alongside the redirects
+var indexKeys = []string{reverseIndexKey, regexIndexKey}
+
+// GetTotalCount gets the total number of redirects selected by the filter. Every redirect is counted by taking
+// the size of the store, less the indexes it also holds, when nothing is filtered out. Otherwise the selected
+// redirect keys are counted by scanning them.
+func (ds *Datastore) GetTotalCount(ctx context.Context, filter RedirectFilter) (totalCount int, err error) {
+	if filter == (RedirectFilter{}) {
+		return ds.countAllRedirects(ctx)
+	}
+
+	var cursor uint64
+
+	for {
+		keyValuePairs, newCursor, err := ds.Backend.GetKeyValuePairs(ctx, filter.matchPattern(), scanCount, cursor)
+		if err != nil {
+			return -1, err
+		}
+
+		for key := range keyValuePairs {
+			if filter.Matches(key) {
+				totalCount++
+			}
+		}
+
+		if newCursor == 0 {
+			return totalCount, nil
+		}
+		cursor = newCursor
+	}
+}
+
+// countAllRedirects counts every redirect in the store from its size, without scanning it. The indexes are
+// checked one at a time, as they are in different hash slots and a cluster rejects a command across slots.
+func (ds *Datastore) countAllRedirects(ctx context.Context) (int, error) {
+	totalKeys, err := ds.Backend.GetTotalKeys(ctx)
 	if err != nil {
 		return -1, err
 	}
-	totalCount = int(totalKeys)
-	return totalCount, err
+
+	for _, key := range indexKeys {
+		exists, err := ds.Backend.CountExistingKeys(ctx, key)
+		if err != nil {
+			return -1, err
+		}
+		totalKeys -= exists
+	}
+
+	return int(totalKeys), nil
 }
 
 func (ds *Datastore) GetValue(ctx context.Context, redirectID string) (string, error) {
@@ -63,3 +513,88 @@ func (ds *Datastore) UpsertValue(ctx context.Context, key string, value interfac
 func (ds *Datastore) DeleteValue(ctx context.Context, redirectID string) error {
 	return ds.Backend.DeleteValue(ctx, redirectID)
 }
+
+// encodeRedirect returns the value to be persisted for the given redirect
+func encodeRedirect(redirect *models.Redirect) (string, error) {
+	stored := storedRedirect{
+		To:          redirect.To,
+		Type:        redirect.Type,
+		StatusCode:  redirect.StatusCode,
+		QueryPolicy: redirect.QueryPolicy,
+		CreatedAt:   redirect.CreatedAt,
+		CreatedBy:   redirect.CreatedBy,
+		UpdatedAt:   redirect.UpdatedAt,
+		UpdatedBy:   redirect.UpdatedBy,
+		ExpiresAt:   redirect.ExpiresAt,
+		ValidFrom:   redirect.ValidFrom,
+		ValidUntil:  redirect.ValidUntil,
+	}
+
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// decodeRedirect builds a redirect from a persisted value. Values written before redirects were stored
+// as JSON are plain target strings, so these are treated as permanent redirects.
+func decodeRedirect(key, value string) (*models.Redirect, error) {
+	host, from := models.ParseRedirectKey(key)
+	redirect := &models.Redirect{
+		Host:        host,
+		From:        from,
+		Type:        models.RedirectTypeExact,
+		StatusCode:  models.DefaultStatusCode,
+		QueryPolicy: models.DefaultQueryPolicy,
+	}
+
+	if !strings.HasPrefix(value, "{") {
+		redirect.To = value
+		return redirect, nil
+	}
+
+	var stored storedRedirect
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		return nil, err
+	}
+
+	redirect.To = stored.To
+	if stored.Type != "" {
+		redirect.Type = stored.Type
+	}
+	redirect.CreatedAt = stored.CreatedAt
+	redirect.CreatedBy = stored.CreatedBy
+	redirect.UpdatedAt = stored.UpdatedAt
+	redirect.UpdatedBy = stored.UpdatedBy
+	redirect.ExpiresAt = stored.ExpiresAt
+	redirect.ValidFrom = stored.ValidFrom
+	redirect.ValidUntil = stored.ValidUntil
+	if stored.StatusCode != 0 {
+		redirect.StatusCode = stored.StatusCode
+	}
+	if stored.QueryPolicy != "" {
+		redirect.QueryPolicy = stored.QueryPolicy
+	}
+
+	return redirect, nil
+}
+
+// matchCandidates returns the keys that could hold a redirect for the given path, in the order they should
+// be tried: the path itself followed by the prefix keys of the path and each of its parents, longest first.
+func matchCandidates(path string) []string {
+	candidates := []string{path}
+
+	prefix := strings.TrimSuffix(path, "/")
+	for {
+		candidates = append(candidates, prefix+models.PrefixWildcard)
+		i := strings.LastIndex(prefix, "/")
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+
+	return candidates
+}
@@ -0,0 +1,655 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	disRedis "github.com/ONSdigital/dis-redis"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var errRedis = errors.New("redis error")
+
+func TestMatchRedirect(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	Convey("Given a store with exact and prefix redirects", t, func() {
+		mockStorer := storetest.NewMapStorer(map[string]string{
+			"/economy/old-page":              "/economy/new-page",
+			"/economy/*":                     `{"to":"/business/*","type":"prefix"}`,
+			"/economy/inflation/*":           `{"to":"/prices/*","type":"prefix"}`,
+			"/economy/inflation/scheduled/*": `{"to":"/later/*","type":"prefix","valid_from":"` + now.Add(time.Hour).Format(time.RFC3339) + `"}`,
+		})
+		datastore := store.Datastore{Backend: mockStorer}
+
+		Convey("When the path has an exact match", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/economy/old-page", now)
+
+			Convey("Then the exact redirect is returned", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, "/economy/old-page")
+				So(redirect.TargetFor("/economy/old-page"), ShouldEqual, "/economy/new-page")
+			})
+		})
+
+		Convey("When the path matches more than one prefix", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/economy/inflation/cpi/latest", now)
+
+			Convey("Then the redirect with the longest prefix is returned", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, "/economy/inflation/*")
+				So(redirect.Type, ShouldEqual, models.RedirectTypePrefix)
+				So(redirect.TargetFor("/economy/inflation/cpi/latest"), ShouldEqual, "/prices/cpi/latest")
+			})
+
+			Convey("And the candidates are looked up from the longest to the shortest", func() {
+				calls := mockStorer.GetValueCalls()
+				So(calls, ShouldHaveLength, 4)
+				So(calls[0].Key, ShouldEqual, "/economy/inflation/cpi/latest")
+				So(calls[1].Key, ShouldEqual, "/economy/inflation/cpi/latest/*")
+				So(calls[2].Key, ShouldEqual, "/economy/inflation/cpi/*")
+				So(calls[3].Key, ShouldEqual, "/economy/inflation/*")
+			})
+		})
+
+		Convey("When the longest matching prefix is not yet active", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/economy/inflation/scheduled/page", now)
+
+			Convey("Then the next longest prefix is used", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, "/economy/inflation/*")
+			})
+		})
+
+		Convey("When nothing matches the path", func() {
+			_, err := datastore.MatchRedirect(ctx, "", "/business/page", now)
+
+			Convey("Then a key not found error is returned", func() {
+				So(err, ShouldEqual, disRedis.ErrKeyNotFound)
+			})
+		})
+	})
+
+	Convey("Given a store with exact, regex and prefix redirects", t, func() {
+		mockStorer := storetest.NewMapStorer(map[string]string{
+			"/datasets/cpih01":                "/datasets/cpih01/editions",
+			`^/datasets/(\w+)/(\d{4})$`:       `{"to":"/datasets/$1/editions/$2","type":"regex"}`,
+			`^/datasets/(\w+)/(\d{4})/?(.*)$`: `{"to":"/datasets/$1/other/$3","type":"regex"}`,
+			`^/datasets/(\w+)/scheduled$`:     `{"to":"/later","type":"regex","valid_from":"` + now.Add(time.Hour).Format(time.RFC3339) + `"}`,
+			"/datasets/*":                     `{"to":"/data/*","type":"prefix"}`,
+		})
+		datastore := store.Datastore{Backend: mockStorer}
+		_, err := datastore.RebuildIndexes(ctx)
+		So(err, ShouldBeNil)
+		scans := len(mockStorer.GetKeyValuePairsCalls())
+
+		Convey("When the path has an exact match", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01", now)
+
+			Convey("Then the exact redirect is returned without looking at regex redirects", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, "/datasets/cpih01")
+				So(mockStorer.GetSortedSetRangeByLexCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the path matches more than one regex redirect", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01/2024", now)
+
+			Convey("Then the first matching pattern is used ahead of any prefix redirect", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, `^/datasets/(\w+)/(\d{4})$`)
+				So(redirect.TargetFor("/datasets/cpih01/2024"), ShouldEqual, "/datasets/cpih01/editions/2024")
+			})
+
+			Convey("And the regex redirects are looked up in the regex index rather than by scanning the store", func() {
+				So(mockStorer.GetKeyValuePairsCalls(), ShouldHaveLength, scans)
+				So(mockStorer.GetSortedSetRangeByLexCalls(), ShouldHaveLength, 1)
+				So(mockStorer.GetSortedSetRangeByLexCalls()[0].Key, ShouldEqual, "index:regex")
+			})
+
+			Convey("And only the regex redirects whose pattern matches are read", func() {
+				for _, call := range mockStorer.GetValueCalls() {
+					So(call.Key, ShouldNotEqual, `^/datasets/(\w+)/scheduled$`)
+				}
+			})
+		})
+
+		Convey("When the only matching regex redirect is not yet active", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01/scheduled", now)
+
+			Convey("Then the prefix redirect is used", func() {
+				So(err, ShouldBeNil)
+				So(redirect.From, ShouldEqual, "/datasets/*")
+			})
+		})
+	})
+
+	Convey("Given a store with host scoped and global redirects", t, func() {
+		mockStorer := storetest.NewMapStorer(map[string]string{
+			"/census":                              "/people",
+			"/economy/*":                           `{"to":"/business/*","type":"prefix"}`,
+			"//cy.ons.gov.uk/economy/*":            `{"to":"/economi/*","type":"prefix"}`,
+			`//cy.ons.gov.uk^/datasets/(\w+)$`:     `{"to":"/setiau-data/$1","type":"regex"}`,
+			`//cy.ons.gov.uk.example.com/datasets`: "/example",
+		})
+		datastore := store.Datastore{Backend: mockStorer}
+		_, err := datastore.RebuildIndexes(ctx)
+		So(err, ShouldBeNil)
+
+		Convey("When a path is matched by a redirect scoped to the host", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "cy.ons.gov.uk", "/economy/inflation", now)
+
+			Convey("Then it is used ahead of any global redirect", func() {
+				So(err, ShouldBeNil)
+				So(redirect.Host, ShouldEqual, "cy.ons.gov.uk")
+				So(redirect.From, ShouldEqual, "/economy/*")
+				So(redirect.TargetFor("/economy/inflation"), ShouldEqual, "/economi/inflation")
+			})
+		})
+
+		Convey("When a path is matched by a regex redirect scoped to the host", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "cy.ons.gov.uk", "/datasets/cpih01", now)
+
+			Convey("Then the regex redirect is used", func() {
+				So(err, ShouldBeNil)
+				So(redirect.Host, ShouldEqual, "cy.ons.gov.uk")
+				So(redirect.TargetFor("/datasets/cpih01"), ShouldEqual, "/setiau-data/cpih01")
+			})
+		})
+
+		Convey("When a path is not matched by any redirect scoped to the host", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "cy.ons.gov.uk", "/census", now)
+
+			Convey("Then the global redirect is used", func() {
+				So(err, ShouldBeNil)
+				So(redirect.Host, ShouldBeEmpty)
+				So(redirect.From, ShouldEqual, "/census")
+			})
+		})
+
+		Convey("When a path is matched without a host", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/economy/inflation", now)
+
+			Convey("Then only global redirects are used", func() {
+				So(err, ShouldBeNil)
+				So(redirect.Host, ShouldBeEmpty)
+				So(redirect.From, ShouldEqual, "/economy/*")
+			})
+		})
+
+		Convey("When a path matched by a regex redirect scoped to a host is matched without a host", func() {
+			_, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01", now)
+
+			Convey("Then the regex redirect is not used", func() {
+				So(err, ShouldEqual, disRedis.ErrKeyNotFound)
+			})
+		})
+
+		Convey("When the redirects for a host are listed", func() {
+			redirects, _, err := datastore.GetRedirects(ctx, store.RedirectFilter{Host: "cy.ons.gov.uk"}, 10, 0)
+
+			Convey("Then only the redirects scoped to that host are returned", func() {
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 2)
+				So(redirects[0].Key(), ShouldEqual, "//cy.ons.gov.uk/economy/*")
+				So(redirects[1].Key(), ShouldEqual, `//cy.ons.gov.uk^/datasets/(\w+)$`)
+			})
+		})
+
+		Convey("When the redirects for a host are counted", func() {
+			count, err := datastore.GetTotalCount(ctx, store.RedirectFilter{Host: "cy.ons.gov.uk"})
+
+			Convey("Then only the redirects scoped to that host are counted", func() {
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given a store that returns an error", t, func() {
+		datastore := store.Datastore{Backend: &storetest.StorerMock{
+			GetValueFunc: func(_ context.Context, _ string) (string, error) {
+				return "", errRedis
+			},
+		}}
+
+		Convey("When a redirect is matched", func() {
+			_, err := datastore.MatchRedirect(ctx, "", "/economy", now)
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldEqual, errRedis)
+			})
+		})
+	})
+}
+
+func TestWalkRedirects(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store whose redirects are returned over more than one page", t, func() {
+		pages := map[uint64]map[string]string{
+			0: {"/economy": "/business"},
+			7: {"/economy/*": `{"to":"/business/*","type":"prefix"}`, "/census": "/people", "to:/people": `["/census"]`},
+		}
+		nextCursors := map[uint64]uint64{0: 7, 7: 0}
+		mockStorer := &storetest.StorerMock{
+			GetKeyValuePairsFunc: func(_ context.Context, _ string, _ int64, cursor uint64) (map[string]string, uint64, error) {
+				return pages[cursor], nextCursors[cursor], nil
+			},
+		}
+		datastore := store.Datastore{Backend: mockStorer}
+
+		Convey("When the redirects are walked", func() {
+			var walked []string
+			err := datastore.WalkRedirects(ctx, store.RedirectFilter{}, func(redirect *models.Redirect) error {
+				walked = append(walked, redirect.From)
+				return nil
+			})
+
+			Convey("Then every redirect on every page is visited in order of key within each page", func() {
+				So(err, ShouldBeNil)
+				So(walked, ShouldResemble, []string{"/economy", "/census", "/economy/*"})
+				So(mockStorer.GetKeyValuePairsCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the redirects selected by a filter are walked", func() {
+			var walked []string
+			err := datastore.WalkRedirects(ctx, store.RedirectFilter{Prefix: "/economy"}, func(redirect *models.Redirect) error {
+				walked = append(walked, redirect.From)
+				return nil
+			})
+
+			Convey("Then only the selected redirects are visited", func() {
+				So(err, ShouldBeNil)
+				So(walked, ShouldResemble, []string{"/economy", "/economy/*"})
+				So(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, `*/economy*`)
+			})
+		})
+
+		Convey("When the function returns an error", func() {
+			err := datastore.WalkRedirects(ctx, store.RedirectFilter{}, func(_ *models.Redirect) error {
+				return errRedis
+			})
+
+			Convey("Then walking stops and the error is returned", func() {
+				So(err, ShouldEqual, errRedis)
+				So(mockStorer.GetKeyValuePairsCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestGetTotalCount(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store with indexed redirects", t, func() {
+		values := map[string]string{}
+		mockStorer := storetest.NewMapStorer(values)
+		datastore := store.Datastore{Backend: mockStorer}
+
+		So(datastore.CreateRedirect(ctx, &models.Redirect{From: "/economy", To: "/business"}, 0), ShouldBeNil)
+		So(datastore.CreateRedirect(ctx, &models.Redirect{Host: "cy.ons.gov.uk", From: "/economy", To: "/business"}, 0), ShouldBeNil)
+		So(datastore.CreateRedirect(ctx, &models.Redirect{From: `^/datasets/(\w+)$`, To: "/data/$1", Type: models.RedirectTypeRegex}, 0), ShouldBeNil)
+
+		Convey("When every redirect is counted", func() {
+			count, err := datastore.GetTotalCount(ctx, store.RedirectFilter{})
+
+			Convey("Then the indexes are left out of the size of the store without scanning it", func() {
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 3)
+				So(mockStorer.GetTotalKeysCalls(), ShouldHaveLength, 1)
+				So(mockStorer.GetKeyValuePairsCalls(), ShouldBeEmpty)
+			})
+
+			Convey("And each index is checked on its own, as the indexes are in different hash slots", func() {
+				calls := mockStorer.CountExistingKeysCalls()
+				So(calls, ShouldHaveLength, 2)
+				for _, call := range calls {
+					So(call.Keys, ShouldHaveLength, 1)
+				}
+			})
+		})
+
+		Convey("When the redirects selected by a filter are counted", func() {
+			count, err := datastore.GetTotalCount(ctx, store.RedirectFilter{Host: "cy.ons.gov.uk"})
+
+			Convey("Then the selected redirects are counted by scanning the store", func() {
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 1)
+				So(mockStorer.GetTotalKeysCalls(), ShouldBeEmpty)
+				So(mockStorer.GetKeyValuePairsCalls(), ShouldNotBeEmpty)
+			})
+		})
+
+		Convey("When the size of the store cannot be read", func() {
+			mockStorer.GetTotalKeysFunc = func(_ context.Context) (int64, error) {
+				return 0, errRedis
+			}
+			_, err := datastore.GetTotalCount(ctx, store.RedirectFilter{})
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldEqual, errRedis)
+			})
+		})
+	})
+}
+
+func TestReverseIndex(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store with redirects to the same target", t, func() {
+		values := map[string]string{}
+		datastore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+
+		So(datastore.CreateRedirect(ctx, &models.Redirect{From: "/economy", To: "/business"}, 0), ShouldBeNil)
+		So(datastore.CreateRedirect(ctx, &models.Redirect{Host: "cy.ons.gov.uk", From: "/economy", To: "/business?lang=cy"}, 0), ShouldBeNil)
+		So(datastore.CreateRedirect(ctx, &models.Redirect{From: "/census", To: "/people"}, 0), ShouldBeNil)
+
+		Convey("When the redirects to the target are listed", func() {
+			redirects, err := datastore.GetRedirectsTo(ctx, "/business")
+
+			Convey("Then every redirect landing on the target is returned, ignoring query strings", func() {
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 2)
+				So(redirects[0].Key(), ShouldEqual, "//cy.ons.gov.uk/economy")
+				So(redirects[1].Key(), ShouldEqual, "/economy")
+			})
+		})
+
+		Convey("When the redirects are listed or counted", func() {
+			redirects, _, err := datastore.GetRedirects(ctx, store.RedirectFilter{}, 10, 0)
+			So(err, ShouldBeNil)
+			count, err := datastore.GetTotalCount(ctx, store.RedirectFilter{})
+			So(err, ShouldBeNil)
+
+			Convey("Then the reverse index is left out", func() {
+				So(redirects, ShouldHaveLength, 3)
+				So(count, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When a redirect is changed to a different target", func() {
+			_, version, err := datastore.GetRedirectWithVersion(ctx, "/economy")
+			So(err, ShouldBeNil)
+			So(datastore.UpdateRedirect(ctx, &models.Redirect{From: "/economy", To: "/people"}, version, 0), ShouldBeNil)
+
+			Convey("Then it is moved to the reverse index of the new target", func() {
+				business, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(business, ShouldHaveLength, 1)
+				So(business[0].Key(), ShouldEqual, "//cy.ons.gov.uk/economy")
+
+				people, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(people, ShouldHaveLength, 2)
+				So(people[0].Key(), ShouldEqual, "/census")
+				So(people[1].Key(), ShouldEqual, "/economy")
+			})
+		})
+
+		Convey("When every redirect to the target is deleted", func() {
+			So(datastore.DeleteRedirect(ctx, "/economy"), ShouldBeNil)
+			So(datastore.DeleteRedirect(ctx, "//cy.ons.gov.uk/economy"), ShouldBeNil)
+
+			Convey("Then they are removed from the reverse index of the target", func() {
+				redirects, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldBeEmpty)
+
+				people, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(people, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a redirect in the reverse index has expired", func() {
+			delete(values, "/economy")
+
+			Convey("Then it is not returned", func() {
+				redirects, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 1)
+				So(redirects[0].Key(), ShouldEqual, "//cy.ons.gov.uk/economy")
+			})
+		})
+
+		Convey("When a redirect that does not exist is deleted", func() {
+			err := datastore.DeleteRedirect(ctx, "/missing")
+
+			Convey("Then the key not found error is returned", func() {
+				So(err, ShouldEqual, disRedis.ErrKeyNotFound)
+			})
+		})
+	})
+}
+
+func TestReverseIndexConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an empty store", t, func() {
+		values := map[string]string{}
+		datastore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+
+		Convey("When many redirects to the same target are written at once", func() {
+			const writers = 50
+			errs := make(chan error, writers)
+			start := make(chan struct{})
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					<-start
+					errs <- datastore.CreateRedirect(ctx, &models.Redirect{From: fmt.Sprintf("/source/%d", i), To: "/target"}, 0)
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+			close(errs)
+
+			Convey("Then every redirect is recorded in the reverse index of the target", func() {
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+
+				redirects, err := datastore.GetRedirectsTo(ctx, "/target")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, writers)
+			})
+		})
+	})
+}
+
+func TestRegexIndex(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	Convey("Given a store with a regex redirect", t, func() {
+		values := map[string]string{}
+		datastore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+
+		regex := &models.Redirect{From: `^/datasets/(\w+)$`, To: "/data/$1", Type: models.RedirectTypeRegex}
+		So(datastore.CreateRedirect(ctx, regex, 0), ShouldBeNil)
+
+		Convey("Then it is matched from the regex index", func() {
+			redirect, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01", now)
+			So(err, ShouldBeNil)
+			So(redirect.Key(), ShouldEqual, regex.Key())
+		})
+
+		Convey("When it is deleted", func() {
+			So(datastore.DeleteRedirect(ctx, regex.Key()), ShouldBeNil)
+
+			Convey("Then it is removed from the regex index", func() {
+				var removed []string
+				for _, call := range datastore.Backend.(*storetest.StorerMock).RemoveFromSortedSetCalls() {
+					if call.Key == "index:regex" {
+						removed = append(removed, call.Members...)
+					}
+				}
+				So(removed, ShouldResemble, []string{regex.Key()})
+			})
+		})
+
+		Convey("When it has expired", func() {
+			delete(values, regex.Key())
+
+			Convey("Then it is not matched", func() {
+				_, err := datastore.MatchRedirect(ctx, "", "/datasets/cpih01", now)
+				So(err, ShouldEqual, disRedis.ErrKeyNotFound)
+			})
+		})
+	})
+}
+
+func TestRebuildIndexes(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store with redirects written before the reverse index existed", t, func() {
+		values := map[string]string{
+			"/economy":               "/business",
+			"//cy.ons.gov.uk/census": `{"to":"/business?lang=cy"}`,
+			"/census":                "/people",
+		}
+		datastore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+
+		Convey("When the indexes are rebuilt", func() {
+			count, err := datastore.RebuildIndexes(ctx)
+			So(err, ShouldBeNil)
+
+			Convey("Then every redirect is indexed", func() {
+				So(count, ShouldEqual, 3)
+
+				redirects, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 2)
+				So(redirects[0].Key(), ShouldEqual, "//cy.ons.gov.uk/census")
+				So(redirects[1].Key(), ShouldEqual, "/economy")
+			})
+
+			Convey("And rebuilding them again changes nothing", func() {
+				count, err := datastore.RebuildIndexes(ctx)
+				So(err, ShouldBeNil)
+				So(count, ShouldEqual, 3)
+
+				redirects, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the store fails while walking the redirects", func() {
+			datastore.Backend.(*storetest.StorerMock).GetKeyValuePairsFunc = func(_ context.Context, _ string, _ int64, _ uint64) (map[string]string, uint64, error) {
+				return nil, 0, errRedis
+			}
+			_, err := datastore.RebuildIndexes(ctx)
+
+			Convey("Then the error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store with a redirect", t, func() {
+		values := map[string]string{}
+		datastore := store.Datastore{Backend: storetest.NewMapStorer(values)}
+		So(datastore.CreateRedirect(ctx, &models.Redirect{From: "/economy", To: "/business"}, 0), ShouldBeNil)
+
+		_, version, err := datastore.GetRedirectWithVersion(ctx, "/economy")
+		So(err, ShouldBeNil)
+		So(version, ShouldEqual, values["/economy"])
+
+		Convey("When a redirect is created from a path that is not redirected", func() {
+			err := datastore.CreateRedirect(ctx, &models.Redirect{From: "/census", To: "/people"}, 0)
+
+			Convey("Then it is stored and added to the reverse index", func() {
+				So(err, ShouldBeNil)
+				redirects, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldHaveLength, 1)
+				So(redirects[0].Key(), ShouldEqual, "/census")
+			})
+		})
+
+		Convey("When a redirect is created from the path that is already redirected", func() {
+			err := datastore.CreateRedirect(ctx, &models.Redirect{From: "/economy", To: "/people"}, 0)
+
+			Convey("Then the value changed error is returned and the redirect is left alone", func() {
+				So(err, ShouldEqual, store.ErrValueChanged)
+				So(values["/economy"], ShouldEqual, version)
+				redirects, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect is updated from the version that is stored", func() {
+			err := datastore.UpdateRedirect(ctx, &models.Redirect{From: "/economy", To: "/people"}, version, 0)
+
+			Convey("Then it is replaced and moved in the reverse index", func() {
+				So(err, ShouldBeNil)
+				redirect, err := datastore.GetRedirect(ctx, "/economy")
+				So(err, ShouldBeNil)
+				So(redirect.To, ShouldEqual, "/people")
+
+				business, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(business, ShouldBeEmpty)
+
+				people, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(people, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the redirect is updated from a version that has since been changed", func() {
+			So(datastore.UpdateRedirect(ctx, &models.Redirect{From: "/economy", To: "/census"}, version, 0), ShouldBeNil)
+			err := datastore.UpdateRedirect(ctx, &models.Redirect{From: "/economy", To: "/people"}, version, 0)
+
+			Convey("Then the value changed error is returned and the newer redirect is kept", func() {
+				So(err, ShouldEqual, store.ErrValueChanged)
+				redirect, err := datastore.GetRedirect(ctx, "/economy")
+				So(err, ShouldBeNil)
+				So(redirect.To, ShouldEqual, "/census")
+				redirects, err := datastore.GetRedirectsTo(ctx, "/people")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect is deleted at the version that is stored", func() {
+			err := datastore.DeleteRedirectIfUnchanged(ctx, "/economy", version)
+
+			Convey("Then it is deleted and removed from the reverse index", func() {
+				So(err, ShouldBeNil)
+				So(values, ShouldNotContainKey, "/economy")
+				redirects, err := datastore.GetRedirectsTo(ctx, "/business")
+				So(err, ShouldBeNil)
+				So(redirects, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the redirect is deleted at a version that has since been deleted", func() {
+			So(datastore.DeleteRedirect(ctx, "/economy"), ShouldBeNil)
+			err := datastore.DeleteRedirectIfUnchanged(ctx, "/economy", version)
+
+			Convey("Then the value changed error is returned", func() {
+				So(err, ShouldEqual, store.ErrValueChanged)
+			})
+		})
+	})
+}
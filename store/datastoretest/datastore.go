@@ -21,24 +21,48 @@ var _ store.Storer = &StorerMock{}
 //
 // 		// make and configure a mocked store.Storer
 // 		mockedStorer := &StorerMock{
+// 			AddToSortedSetFunc: func(ctx context.Context, key string, members ...string) error {
+// 				panic("mock out the AddToSortedSet method")
+// 			},
 // 			CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
 // 				panic("mock out the Checker method")
 // 			},
+// 			CompareAndDeleteValueFunc: func(ctx context.Context, key string, expected string) error {
+// 				panic("mock out the CompareAndDeleteValue method")
+// 			},
+// 			CompareAndSwapValueFunc: func(ctx context.Context, key string, expected string, value interface{}, expiration time.Duration) error {
+// 				panic("mock out the CompareAndSwapValue method")
+// 			},
+// 			CountExistingKeysFunc: func(ctx context.Context, keys ...string) (int64, error) {
+// 				panic("mock out the CountExistingKeys method")
+// 			},
 // 			DeleteValueFunc: func(ctx context.Context, key string) error {
 // 				panic("mock out the DeleteValue method")
 // 			},
+// 			DeleteValueReturningPreviousFunc: func(ctx context.Context, key string) (string, error) {
+// 				panic("mock out the DeleteValueReturningPrevious method")
+// 			},
 // 			GetKeyValuePairsFunc: func(ctx context.Context, matchPattern string, count int64, cursor uint64) (map[string]string, uint64, error) {
 // 				panic("mock out the GetKeyValuePairs method")
 // 			},
+// 			GetSortedSetRangeByLexFunc: func(ctx context.Context, key string, min string, max string) ([]string, error) {
+// 				panic("mock out the GetSortedSetRangeByLex method")
+// 			},
 // 			GetTotalKeysFunc: func(ctx context.Context) (int64, error) {
 // 				panic("mock out the GetTotalKeys method")
 // 			},
 // 			GetValueFunc: func(ctx context.Context, key string) (string, error) {
 // 				panic("mock out the GetValue method")
 // 			},
+// 			RemoveFromSortedSetFunc: func(ctx context.Context, key string, members ...string) error {
+// 				panic("mock out the RemoveFromSortedSet method")
+// 			},
 // 			SetValueFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 // 				panic("mock out the SetValue method")
 // 			},
+// 			SetValueIfAbsentFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+// 				panic("mock out the SetValueIfAbsent method")
+// 			},
 // 		}
 //
 // 		// use mockedStorer in code that requires store.Storer
@@ -46,26 +70,59 @@ var _ store.Storer = &StorerMock{}
 //
 // 	}
 type StorerMock struct {
+	// AddToSortedSetFunc mocks the AddToSortedSet method.
+	AddToSortedSetFunc func(ctx context.Context, key string, members ...string) error
+
 	// CheckerFunc mocks the Checker method.
 	CheckerFunc func(ctx context.Context, state *healthcheck.CheckState) error
 
+	// CompareAndDeleteValueFunc mocks the CompareAndDeleteValue method.
+	CompareAndDeleteValueFunc func(ctx context.Context, key string, expected string) error
+
+	// CompareAndSwapValueFunc mocks the CompareAndSwapValue method.
+	CompareAndSwapValueFunc func(ctx context.Context, key string, expected string, value interface{}, expiration time.Duration) error
+
+	// CountExistingKeysFunc mocks the CountExistingKeys method.
+	CountExistingKeysFunc func(ctx context.Context, keys ...string) (int64, error)
+
 	// DeleteValueFunc mocks the DeleteValue method.
 	DeleteValueFunc func(ctx context.Context, key string) error
 
+	// DeleteValueReturningPreviousFunc mocks the DeleteValueReturningPrevious method.
+	DeleteValueReturningPreviousFunc func(ctx context.Context, key string) (string, error)
+
 	// GetKeyValuePairsFunc mocks the GetKeyValuePairs method.
 	GetKeyValuePairsFunc func(ctx context.Context, matchPattern string, count int64, cursor uint64) (map[string]string, uint64, error)
 
+	// GetSortedSetRangeByLexFunc mocks the GetSortedSetRangeByLex method.
+	GetSortedSetRangeByLexFunc func(ctx context.Context, key string, min string, max string) ([]string, error)
+
 	// GetTotalKeysFunc mocks the GetTotalKeys method.
 	GetTotalKeysFunc func(ctx context.Context) (int64, error)
 
 	// GetValueFunc mocks the GetValue method.
 	GetValueFunc func(ctx context.Context, key string) (string, error)
 
+	// RemoveFromSortedSetFunc mocks the RemoveFromSortedSet method.
+	RemoveFromSortedSetFunc func(ctx context.Context, key string, members ...string) error
+
 	// SetValueFunc mocks the SetValue method.
 	SetValueFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 
+	// SetValueIfAbsentFunc mocks the SetValueIfAbsent method.
+	SetValueIfAbsentFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+
 	// calls tracks calls to the methods.
 	calls struct {
+		// AddToSortedSet holds details about calls to the AddToSortedSet method.
+		AddToSortedSet []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Members is the members argument value.
+			Members []string
+		}
 		// Checker holds details about calls to the Checker method.
 		Checker []struct {
 			// Ctx is the ctx argument value.
@@ -73,6 +130,35 @@ type StorerMock struct {
 			// State is the state argument value.
 			State *healthcheck.CheckState
 		}
+		// CompareAndDeleteValue holds details about calls to the CompareAndDeleteValue method.
+		CompareAndDeleteValue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Expected is the expected argument value.
+			Expected string
+		}
+		// CompareAndSwapValue holds details about calls to the CompareAndSwapValue method.
+		CompareAndSwapValue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Expected is the expected argument value.
+			Expected string
+			// Value is the value argument value.
+			Value interface{}
+			// Expiration is the expiration argument value.
+			Expiration time.Duration
+		}
+		// CountExistingKeys holds details about calls to the CountExistingKeys method.
+		CountExistingKeys []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Keys is the keys argument value.
+			Keys []string
+		}
 		// DeleteValue holds details about calls to the DeleteValue method.
 		DeleteValue []struct {
 			// Ctx is the ctx argument value.
@@ -80,6 +166,13 @@ type StorerMock struct {
 			// Key is the key argument value.
 			Key string
 		}
+		// DeleteValueReturningPrevious holds details about calls to the DeleteValueReturningPrevious method.
+		DeleteValueReturningPrevious []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+		}
 		// GetKeyValuePairs holds details about calls to the GetKeyValuePairs method.
 		GetKeyValuePairs []struct {
 			// Ctx is the ctx argument value.
@@ -91,6 +184,17 @@ type StorerMock struct {
 			// Cursor is the cursor argument value.
 			Cursor uint64
 		}
+		// GetSortedSetRangeByLex holds details about calls to the GetSortedSetRangeByLex method.
+		GetSortedSetRangeByLex []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Min is the min argument value.
+			Min string
+			// Max is the max argument value.
+			Max string
+		}
 		// GetTotalKeys holds details about calls to the GetTotalKeys method.
 		GetTotalKeys []struct {
 			// Ctx is the ctx argument value.
@@ -103,6 +207,15 @@ type StorerMock struct {
 			// Key is the key argument value.
 			Key string
 		}
+		// RemoveFromSortedSet holds details about calls to the RemoveFromSortedSet method.
+		RemoveFromSortedSet []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Members is the members argument value.
+			Members []string
+		}
 		// SetValue holds details about calls to the SetValue method.
 		SetValue []struct {
 			// Ctx is the ctx argument value.
@@ -114,13 +227,71 @@ type StorerMock struct {
 			// Expiration is the expiration argument value.
 			Expiration time.Duration
 		}
+		// SetValueIfAbsent holds details about calls to the SetValueIfAbsent method.
+		SetValueIfAbsent []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Key is the key argument value.
+			Key string
+			// Value is the value argument value.
+			Value interface{}
+			// Expiration is the expiration argument value.
+			Expiration time.Duration
+		}
+	}
+	lockAddToSortedSet               sync.RWMutex
+	lockChecker                      sync.RWMutex
+	lockCompareAndDeleteValue        sync.RWMutex
+	lockCompareAndSwapValue          sync.RWMutex
+	lockCountExistingKeys            sync.RWMutex
+	lockDeleteValue                  sync.RWMutex
+	lockDeleteValueReturningPrevious sync.RWMutex
+	lockGetKeyValuePairs             sync.RWMutex
+	lockGetSortedSetRangeByLex       sync.RWMutex
+	lockGetTotalKeys                 sync.RWMutex
+	lockGetValue                     sync.RWMutex
+	lockRemoveFromSortedSet          sync.RWMutex
+	lockSetValue                     sync.RWMutex
+	lockSetValueIfAbsent             sync.RWMutex
+}
+
+// AddToSortedSet calls AddToSortedSetFunc.
+func (mock *StorerMock) AddToSortedSet(ctx context.Context, key string, members ...string) error {
+	if mock.AddToSortedSetFunc == nil {
+		panic("StorerMock.AddToSortedSetFunc: method is nil but Storer.AddToSortedSet was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Key     string
+		Members []string
+	}{
+		Ctx:     ctx,
+		Key:     key,
+		Members: members,
 	}
-	lockChecker          sync.RWMutex
-	lockDeleteValue      sync.RWMutex
-	lockGetKeyValuePairs sync.RWMutex
-	lockGetTotalKeys     sync.RWMutex
-	lockGetValue         sync.RWMutex
-	lockSetValue         sync.RWMutex
+	mock.lockAddToSortedSet.Lock()
+	mock.calls.AddToSortedSet = append(mock.calls.AddToSortedSet, callInfo)
+	mock.lockAddToSortedSet.Unlock()
+	return mock.AddToSortedSetFunc(ctx, key, members...)
+}
+
+// AddToSortedSetCalls gets all the calls that were made to AddToSortedSet.
+// Check the length with:
+//     len(mockedStorer.AddToSortedSetCalls())
+func (mock *StorerMock) AddToSortedSetCalls() []struct {
+	Ctx     context.Context
+	Key     string
+	Members []string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Key     string
+		Members []string
+	}
+	mock.lockAddToSortedSet.RLock()
+	calls = mock.calls.AddToSortedSet
+	mock.lockAddToSortedSet.RUnlock()
+	return calls
 }
 
 // Checker calls CheckerFunc.
@@ -158,6 +329,127 @@ func (mock *StorerMock) CheckerCalls() []struct {
 	return calls
 }
 
+// CompareAndDeleteValue calls CompareAndDeleteValueFunc.
+func (mock *StorerMock) CompareAndDeleteValue(ctx context.Context, key string, expected string) error {
+	if mock.CompareAndDeleteValueFunc == nil {
+		panic("StorerMock.CompareAndDeleteValueFunc: method is nil but Storer.CompareAndDeleteValue was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Key      string
+		Expected string
+	}{
+		Ctx:      ctx,
+		Key:      key,
+		Expected: expected,
+	}
+	mock.lockCompareAndDeleteValue.Lock()
+	mock.calls.CompareAndDeleteValue = append(mock.calls.CompareAndDeleteValue, callInfo)
+	mock.lockCompareAndDeleteValue.Unlock()
+	return mock.CompareAndDeleteValueFunc(ctx, key, expected)
+}
+
+// CompareAndDeleteValueCalls gets all the calls that were made to CompareAndDeleteValue.
+// Check the length with:
+//     len(mockedStorer.CompareAndDeleteValueCalls())
+func (mock *StorerMock) CompareAndDeleteValueCalls() []struct {
+	Ctx      context.Context
+	Key      string
+	Expected string
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Key      string
+		Expected string
+	}
+	mock.lockCompareAndDeleteValue.RLock()
+	calls = mock.calls.CompareAndDeleteValue
+	mock.lockCompareAndDeleteValue.RUnlock()
+	return calls
+}
+
+// CompareAndSwapValue calls CompareAndSwapValueFunc.
+func (mock *StorerMock) CompareAndSwapValue(ctx context.Context, key string, expected string, value interface{}, expiration time.Duration) error {
+	if mock.CompareAndSwapValueFunc == nil {
+		panic("StorerMock.CompareAndSwapValueFunc: method is nil but Storer.CompareAndSwapValue was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Key        string
+		Expected   string
+		Value      interface{}
+		Expiration time.Duration
+	}{
+		Ctx:        ctx,
+		Key:        key,
+		Expected:   expected,
+		Value:      value,
+		Expiration: expiration,
+	}
+	mock.lockCompareAndSwapValue.Lock()
+	mock.calls.CompareAndSwapValue = append(mock.calls.CompareAndSwapValue, callInfo)
+	mock.lockCompareAndSwapValue.Unlock()
+	return mock.CompareAndSwapValueFunc(ctx, key, expected, value, expiration)
+}
+
+// CompareAndSwapValueCalls gets all the calls that were made to CompareAndSwapValue.
+// Check the length with:
+//     len(mockedStorer.CompareAndSwapValueCalls())
+func (mock *StorerMock) CompareAndSwapValueCalls() []struct {
+	Ctx        context.Context
+	Key        string
+	Expected   string
+	Value      interface{}
+	Expiration time.Duration
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Key        string
+		Expected   string
+		Value      interface{}
+		Expiration time.Duration
+	}
+	mock.lockCompareAndSwapValue.RLock()
+	calls = mock.calls.CompareAndSwapValue
+	mock.lockCompareAndSwapValue.RUnlock()
+	return calls
+}
+
+// CountExistingKeys calls CountExistingKeysFunc.
+func (mock *StorerMock) CountExistingKeys(ctx context.Context, keys ...string) (int64, error) {
+	if mock.CountExistingKeysFunc == nil {
+		panic("StorerMock.CountExistingKeysFunc: method is nil but Storer.CountExistingKeys was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Keys []string
+	}{
+		Ctx:  ctx,
+		Keys: keys,
+	}
+	mock.lockCountExistingKeys.Lock()
+	mock.calls.CountExistingKeys = append(mock.calls.CountExistingKeys, callInfo)
+	mock.lockCountExistingKeys.Unlock()
+	return mock.CountExistingKeysFunc(ctx, keys...)
+}
+
+// CountExistingKeysCalls gets all the calls that were made to CountExistingKeys.
+// Check the length with:
+//     len(mockedStorer.CountExistingKeysCalls())
+func (mock *StorerMock) CountExistingKeysCalls() []struct {
+	Ctx  context.Context
+	Keys []string
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Keys []string
+	}
+	mock.lockCountExistingKeys.RLock()
+	calls = mock.calls.CountExistingKeys
+	mock.lockCountExistingKeys.RUnlock()
+	return calls
+}
+
 // DeleteValue calls DeleteValueFunc.
 func (mock *StorerMock) DeleteValue(ctx context.Context, key string) error {
 	if mock.DeleteValueFunc == nil {
@@ -193,6 +485,41 @@ func (mock *StorerMock) DeleteValueCalls() []struct {
 	return calls
 }
 
+// DeleteValueReturningPrevious calls DeleteValueReturningPreviousFunc.
+func (mock *StorerMock) DeleteValueReturningPrevious(ctx context.Context, key string) (string, error) {
+	if mock.DeleteValueReturningPreviousFunc == nil {
+		panic("StorerMock.DeleteValueReturningPreviousFunc: method is nil but Storer.DeleteValueReturningPrevious was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+	}{
+		Ctx: ctx,
+		Key: key,
+	}
+	mock.lockDeleteValueReturningPrevious.Lock()
+	mock.calls.DeleteValueReturningPrevious = append(mock.calls.DeleteValueReturningPrevious, callInfo)
+	mock.lockDeleteValueReturningPrevious.Unlock()
+	return mock.DeleteValueReturningPreviousFunc(ctx, key)
+}
+
+// DeleteValueReturningPreviousCalls gets all the calls that were made to DeleteValueReturningPrevious.
+// Check the length with:
+//     len(mockedStorer.DeleteValueReturningPreviousCalls())
+func (mock *StorerMock) DeleteValueReturningPreviousCalls() []struct {
+	Ctx context.Context
+	Key string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+	}
+	mock.lockDeleteValueReturningPrevious.RLock()
+	calls = mock.calls.DeleteValueReturningPrevious
+	mock.lockDeleteValueReturningPrevious.RUnlock()
+	return calls
+}
+
 // GetKeyValuePairs calls GetKeyValuePairsFunc.
 func (mock *StorerMock) GetKeyValuePairs(ctx context.Context, matchPattern string, count int64, cursor uint64) (map[string]string, uint64, error) {
 	if mock.GetKeyValuePairsFunc == nil {
@@ -236,6 +563,49 @@ func (mock *StorerMock) GetKeyValuePairsCalls() []struct {
 	return calls
 }
 
+// GetSortedSetRangeByLex calls GetSortedSetRangeByLexFunc.
+func (mock *StorerMock) GetSortedSetRangeByLex(ctx context.Context, key string, min string, max string) ([]string, error) {
+	if mock.GetSortedSetRangeByLexFunc == nil {
+		panic("StorerMock.GetSortedSetRangeByLexFunc: method is nil but Storer.GetSortedSetRangeByLex was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Key string
+		Min string
+		Max string
+	}{
+		Ctx: ctx,
+		Key: key,
+		Min: min,
+		Max: max,
+	}
+	mock.lockGetSortedSetRangeByLex.Lock()
+	mock.calls.GetSortedSetRangeByLex = append(mock.calls.GetSortedSetRangeByLex, callInfo)
+	mock.lockGetSortedSetRangeByLex.Unlock()
+	return mock.GetSortedSetRangeByLexFunc(ctx, key, min, max)
+}
+
+// GetSortedSetRangeByLexCalls gets all the calls that were made to GetSortedSetRangeByLex.
+// Check the length with:
+//     len(mockedStorer.GetSortedSetRangeByLexCalls())
+func (mock *StorerMock) GetSortedSetRangeByLexCalls() []struct {
+	Ctx context.Context
+	Key string
+	Min string
+	Max string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Key string
+		Min string
+		Max string
+	}
+	mock.lockGetSortedSetRangeByLex.RLock()
+	calls = mock.calls.GetSortedSetRangeByLex
+	mock.lockGetSortedSetRangeByLex.RUnlock()
+	return calls
+}
+
 // GetTotalKeys calls GetTotalKeysFunc.
 func (mock *StorerMock) GetTotalKeys(ctx context.Context) (int64, error) {
 	if mock.GetTotalKeysFunc == nil {
@@ -302,6 +672,45 @@ func (mock *StorerMock) GetValueCalls() []struct {
 	return calls
 }
 
+// RemoveFromSortedSet calls RemoveFromSortedSetFunc.
+func (mock *StorerMock) RemoveFromSortedSet(ctx context.Context, key string, members ...string) error {
+	if mock.RemoveFromSortedSetFunc == nil {
+		panic("StorerMock.RemoveFromSortedSetFunc: method is nil but Storer.RemoveFromSortedSet was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Key     string
+		Members []string
+	}{
+		Ctx:     ctx,
+		Key:     key,
+		Members: members,
+	}
+	mock.lockRemoveFromSortedSet.Lock()
+	mock.calls.RemoveFromSortedSet = append(mock.calls.RemoveFromSortedSet, callInfo)
+	mock.lockRemoveFromSortedSet.Unlock()
+	return mock.RemoveFromSortedSetFunc(ctx, key, members...)
+}
+
+// RemoveFromSortedSetCalls gets all the calls that were made to RemoveFromSortedSet.
+// Check the length with:
+//     len(mockedStorer.RemoveFromSortedSetCalls())
+func (mock *StorerMock) RemoveFromSortedSetCalls() []struct {
+	Ctx     context.Context
+	Key     string
+	Members []string
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Key     string
+		Members []string
+	}
+	mock.lockRemoveFromSortedSet.RLock()
+	calls = mock.calls.RemoveFromSortedSet
+	mock.lockRemoveFromSortedSet.RUnlock()
+	return calls
+}
+
 // SetValue calls SetValueFunc.
 func (mock *StorerMock) SetValue(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	if mock.SetValueFunc == nil {
@@ -344,3 +753,46 @@ func (mock *StorerMock) SetValueCalls() []struct {
 	mock.lockSetValue.RUnlock()
 	return calls
 }
+
+// SetValueIfAbsent calls SetValueIfAbsentFunc.
+func (mock *StorerMock) SetValueIfAbsent(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	if mock.SetValueIfAbsentFunc == nil {
+		panic("StorerMock.SetValueIfAbsentFunc: method is nil but Storer.SetValueIfAbsent was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		Key        string
+		Value      interface{}
+		Expiration time.Duration
+	}{
+		Ctx:        ctx,
+		Key:        key,
+		Value:      value,
+		Expiration: expiration,
+	}
+	mock.lockSetValueIfAbsent.Lock()
+	mock.calls.SetValueIfAbsent = append(mock.calls.SetValueIfAbsent, callInfo)
+	mock.lockSetValueIfAbsent.Unlock()
+	return mock.SetValueIfAbsentFunc(ctx, key, value, expiration)
+}
+
+// SetValueIfAbsentCalls gets all the calls that were made to SetValueIfAbsent.
+// Check the length with:
+//     len(mockedStorer.SetValueIfAbsentCalls())
+func (mock *StorerMock) SetValueIfAbsentCalls() []struct {
+	Ctx        context.Context
+	Key        string
+	Value      interface{}
+	Expiration time.Duration
+} {
+	var calls []struct {
+		Ctx        context.Context
+		Key        string
+		Value      interface{}
+		Expiration time.Duration
+	}
+	mock.lockSetValueIfAbsent.RLock()
+	calls = mock.calls.SetValueIfAbsent
+	mock.lockSetValueIfAbsent.RUnlock()
+	return calls
+}
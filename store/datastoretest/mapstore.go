@@ -0,0 +1,216 @@
+package storetest
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dis-redirect-api/store"
+	disRedis "github.com/ONSdigital/dis-redis"
+)
+
+// NewMapStorer returns a mock store backed by the given map of keys to values, whose operations are each atomic
+// like those of Redis so that it can be written to by more than one goroutine at once. Each operation yields
+// before it runs, as a round trip to Redis would, so that goroutines writing at once are interleaved. Scans honour
+// their match pattern, and the sorted sets used by the indexes are kept alongside the values.
+func NewMapStorer(values map[string]string) *StorerMock {
+	var mu sync.Mutex
+	sortedSets := map[string]map[string]bool{}
+
+	return &StorerMock{
+		GetValueFunc: func(_ context.Context, key string) (string, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := values[key]
+			if !ok {
+				return "", disRedis.ErrKeyNotFound
+			}
+			return value, nil
+		},
+		GetKeyValuePairsFunc: func(_ context.Context, matchPattern string, _ int64, _ uint64) (map[string]string, uint64, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			keyValuePairs := map[string]string{}
+			for key, value := range values {
+				if matchPattern == "" || MatchesPattern(matchPattern, key) {
+					keyValuePairs[key] = value
+				}
+			}
+			return keyValuePairs, 0, nil
+		},
+		GetTotalKeysFunc: func(_ context.Context) (int64, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			return int64(len(values) + len(sortedSets)), nil
+		},
+		CountExistingKeysFunc: func(_ context.Context, keys ...string) (int64, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			var count int64
+			for _, key := range keys {
+				if _, ok := values[key]; ok {
+					count++
+				} else if _, ok := sortedSets[key]; ok {
+					count++
+				}
+			}
+			return count, nil
+		},
+		SetValueFunc: func(_ context.Context, key string, value interface{}, _ time.Duration) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			values[key] = value.(string)
+			return nil
+		},
+		SetValueIfAbsentFunc: func(_ context.Context, key string, value interface{}, _ time.Duration) (bool, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := values[key]; ok {
+				return false, nil
+			}
+			values[key] = value.(string)
+			return true, nil
+		},
+		CompareAndSwapValueFunc: func(_ context.Context, key, expected string, value interface{}, _ time.Duration) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			if current, ok := values[key]; !ok || current != expected {
+				return store.ErrValueChanged
+			}
+			values[key] = value.(string)
+			return nil
+		},
+		DeleteValueFunc: func(_ context.Context, key string) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			delete(values, key)
+			return nil
+		},
+		DeleteValueReturningPreviousFunc: func(_ context.Context, key string) (string, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			value, ok := values[key]
+			if !ok {
+				return "", disRedis.ErrKeyNotFound
+			}
+			delete(values, key)
+			return value, nil
+		},
+		CompareAndDeleteValueFunc: func(_ context.Context, key, expected string) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			if current, ok := values[key]; !ok || current != expected {
+				return store.ErrValueChanged
+			}
+			delete(values, key)
+			return nil
+		},
+		AddToSortedSetFunc: func(_ context.Context, key string, members ...string) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			if sortedSets[key] == nil {
+				sortedSets[key] = map[string]bool{}
+			}
+			for _, member := range members {
+				sortedSets[key][member] = true
+			}
+			return nil
+		},
+		RemoveFromSortedSetFunc: func(_ context.Context, key string, members ...string) error {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			for _, member := range members {
+				delete(sortedSets[key], member)
+			}
+			if len(sortedSets[key]) == 0 {
+				delete(sortedSets, key)
+			}
+			return nil
+		},
+		GetSortedSetRangeByLexFunc: func(_ context.Context, key, min, max string) ([]string, error) {
+			runtime.Gosched()
+			mu.Lock()
+			defer mu.Unlock()
+			members := []string{}
+			for member := range sortedSets[key] {
+				if inLexRange(member, min, max) {
+					members = append(members, member)
+				}
+			}
+			sort.Strings(members)
+			return members, nil
+		},
+	}
+}
+
+// inLexRange returns true if the member of a sorted set is between the bounds given to ZRANGEBYLEX
+func inLexRange(member, min, max string) bool {
+	switch {
+	case min == "-":
+	case strings.HasPrefix(min, "[") && member >= min[1:]:
+	case strings.HasPrefix(min, "(") && member > min[1:]:
+	default:
+		return false
+	}
+
+	switch {
+	case max == "+":
+	case strings.HasPrefix(max, "[") && member <= max[1:]:
+	case strings.HasPrefix(max, "(") && member < max[1:]:
+	default:
+		return false
+	}
+
+	return true
+}
+
+// MatchesPattern returns true if the key is matched by the redis glob style pattern used to scan the store
+func MatchesPattern(pattern, key string) bool {
+	return globToRegexp(pattern).MatchString(key)
+}
+
+// globToRegexp converts the redis glob style patterns used to scan the store into a regular expression
+func globToRegexp(pattern string) *regexp.Regexp {
+	var expr strings.Builder
+	inClass, escaped := false, false
+	for _, c := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		case c == '\\':
+			escaped = true
+		case inClass && c == ']':
+			inClass = false
+			expr.WriteRune(c)
+		case inClass:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		case c == '[':
+			inClass = true
+			expr.WriteRune(c)
+		case c == '*':
+			expr.WriteString(".*")
+		case c == '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return regexp.MustCompile("^" + expr.String() + "$")
+}
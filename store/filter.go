@@ -0,0 +1,93 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/ONSdigital/dis-redirect-api/models"
+)
+
+// globSpecialCharacters are the characters with a special meaning in the patterns used to scan the store
+const globSpecialCharacters = `*?[]\`
+
+// RedirectFilter selects the redirects to list or count. Empty fields select every redirect.
+type RedirectFilter struct {
+	// Host selects the redirects scoped to the host
+	Host string
+	// GlobalOnly selects only the redirects that are not scoped to a host, when no host is given
+	GlobalOnly bool
+	// Prefix selects the redirects whose 'from' path or pattern starts with the prefix
+	Prefix string
+	// Contains selects the redirects whose 'from' path or pattern contains the text
+	Contains string
+}
+
+// Matches returns true if the redirect stored against the given key is selected by the filter
+func (f RedirectFilter) Matches(key string) bool {
+	if !isRedirectKey(key) {
+		return false
+	}
+
+	host, from := models.ParseRedirectKey(key)
+
+	return (host == f.Host || f.Host == "" && !f.GlobalOnly) &&
+		strings.HasPrefix(from, f.Prefix) &&
+		strings.Contains(from, f.Contains)
+}
+
+// matchPattern returns the pattern used to scan the store for the keys of the redirects selected by the filter.
+// A single pattern cannot select the 'from' paths of both global and host scoped redirects exactly, so the
+// pattern can match more keys than the filter selects and the keys found have to be checked with Matches.
+func (f RedirectFilter) matchPattern() string {
+	if f.Host != "" {
+		if f.Prefix != "" {
+			return models.RedirectKey(f.Host, escapeGlob(f.Prefix)+"*")
+		}
+		if f.Contains != "" {
+			return models.RedirectKey(f.Host, redirectKeyPattern+escapeGlob(f.Contains)+"*")
+		}
+		return models.RedirectKey(f.Host, redirectKeyPattern)
+	}
+
+	if f.Prefix != "" {
+		// the key of a global redirect is its 'from' path, so starts with the prefix itself
+		if f.GlobalOnly {
+			return escapeGlob(f.Prefix) + "*"
+		}
+		return containsPattern(f.Prefix)
+	}
+	if f.Contains != "" {
+		return containsPattern(f.Contains)
+	}
+	return redirectKeyPattern
+}
+
+// containsPattern returns the pattern used to scan the store for the keys of global and host scoped redirects
+// containing the given text. The keys of the indexes are not strings, so the pattern must never match them: text
+// starting with a '/' or the regex anchor can never be found in them, and other text has to follow the first
+// character of a redirect key.
+func containsPattern(text string) string {
+	if isRedirectKey(text) {
+		return "*" + escapeGlob(text) + "*"
+	}
+
+	return redirectKeyPattern + escapeGlob(text) + "*"
+}
+
+// isRedirectKey returns true if the given key is the key of a redirect rather than of an index
+func isRedirectKey(key string) bool {
+	return strings.HasPrefix(key, "/") || strings.HasPrefix(key, models.RegexAnchor)
+}
+
+// escapeGlob escapes the characters in the given text that have a special meaning in the patterns used to scan
+// the store, so that the text is matched as it is
+func escapeGlob(text string) string {
+	var escaped strings.Builder
+	for _, c := range text {
+		if strings.ContainsRune(globSpecialCharacters, c) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(c)
+	}
+
+	return escaped.String()
+}
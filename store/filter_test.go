@@ -0,0 +1,129 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ONSdigital/dis-redirect-api/store"
+	storetest "github.com/ONSdigital/dis-redirect-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedirectFilter(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a store with global and host scoped redirects", t, func() {
+		mockStorer := storetest.NewMapStorer(map[string]string{
+			"/economy/bulletin":                "/business/bulletin",
+			"/economy/inflation/*":             `{"to":"/prices/*","type":"prefix"}`,
+			"/census/economy":                  "/people/economy",
+			"/census/*/data":                   "/people/data",
+			`^/economy/(\w+)$`:                 `{"to":"/business/$1","type":"regex"}`,
+			"//cy.ons.gov.uk/economy/bulletin": "/cy/business/bulletin",
+			"//cy.ons.gov.uk/census":           "/cy/people",
+		})
+		datastore := store.Datastore{Backend: mockStorer}
+
+		list := func(filter store.RedirectFilter) []string {
+			redirects, _, err := datastore.GetRedirects(ctx, filter, 10, 0)
+			So(err, ShouldBeNil)
+
+			count, err := datastore.GetTotalCount(ctx, filter)
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, len(redirects))
+
+			keys := []string{}
+			for i := range redirects {
+				keys = append(keys, redirects[i].Key())
+			}
+			return keys
+		}
+
+		Convey("When the redirects are filtered by prefix", func() {
+			keys := list(store.RedirectFilter{Prefix: "/economy/"})
+
+			Convey("Then the global and host scoped redirects from paths starting with the prefix are returned", func() {
+				So(keys, ShouldResemble, []string{"//cy.ons.gov.uk/economy/bulletin", "/economy/bulletin", "/economy/inflation/*"})
+			})
+		})
+
+		Convey("When only the global redirects are filtered by prefix", func() {
+			keys := list(store.RedirectFilter{Prefix: "/economy/", GlobalOnly: true})
+
+			Convey("Then the host scoped redirects are left out", func() {
+				So(keys, ShouldResemble, []string{"/economy/bulletin", "/economy/inflation/*"})
+				So(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, "/economy/*")
+			})
+		})
+
+		Convey("When the redirects are filtered by prefix and host", func() {
+			keys := list(store.RedirectFilter{Host: "cy.ons.gov.uk", Prefix: "/economy/"})
+
+			Convey("Then only the redirects scoped to the host are returned", func() {
+				So(keys, ShouldResemble, []string{"//cy.ons.gov.uk/economy/bulletin"})
+				So(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, "//cy.ons.gov.uk/economy/*")
+			})
+		})
+
+		Convey("When the redirects are filtered by the text their paths contain", func() {
+			keys := list(store.RedirectFilter{Contains: "economy"})
+
+			Convey("Then every redirect from a path containing the text is returned", func() {
+				So(keys, ShouldResemble, []string{
+					"//cy.ons.gov.uk/economy/bulletin",
+					"/census/economy",
+					"/economy/bulletin",
+					"/economy/inflation/*",
+					`^/economy/(\w+)$`,
+				})
+			})
+		})
+
+		Convey("When the redirects are filtered by text that is also found in the keys of the indexes", func() {
+			keys := list(store.RedirectFilter{Contains: "to"})
+
+			Convey("Then the store is scanned with a pattern that never matches the keys of the indexes", func() {
+				So(keys, ShouldBeEmpty)
+				So(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, "[/^]*to*")
+				So(storetest.MatchesPattern(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, "index:to"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When the redirects are filtered by both prefix and the text their paths contain", func() {
+			keys := list(store.RedirectFilter{Prefix: "/economy/", Contains: "economy"})
+
+			Convey("Then the text can overlap the prefix", func() {
+				So(keys, ShouldHaveLength, 3)
+			})
+		})
+
+		Convey("When the prefix contains characters with a special meaning in a scan pattern", func() {
+			keys := list(store.RedirectFilter{Prefix: "/census/*"})
+
+			Convey("Then they are matched as they are", func() {
+				So(keys, ShouldResemble, []string{"/census/*/data"})
+				So(mockStorer.GetKeyValuePairsCalls()[0].MatchPattern, ShouldEqual, `*/census/\**`)
+			})
+		})
+
+		Convey("When the redirects are filtered by the prefix of regex patterns", func() {
+			keys := list(store.RedirectFilter{Prefix: "^/economy"})
+
+			Convey("Then the regex redirects are returned", func() {
+				So(keys, ShouldResemble, []string{`^/economy/(\w+)$`})
+			})
+		})
+	})
+
+	Convey("Given the keys in the store", t, func() {
+		Convey("Then the keys of the indexes are never selected", func() {
+			So(store.RedirectFilter{}.Matches("index:to"), ShouldBeFalse)
+			So(store.RedirectFilter{Contains: "index"}.Matches("index:to"), ShouldBeFalse)
+		})
+
+		Convey("Then the keys of redirects scoped to another host are not selected", func() {
+			So(store.RedirectFilter{Host: "cy.ons.gov.uk"}.Matches("//www.ons.gov.uk/economy"), ShouldBeFalse)
+			So(store.RedirectFilter{Host: "cy.ons.gov.uk"}.Matches("//cy.ons.gov.uk/economy"), ShouldBeTrue)
+		})
+	})
+}
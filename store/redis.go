@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	disRedis "github.com/ONSdigital/dis-redis"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrValueChanged is returned when a value is not written or deleted because the value stored against its key
+// is no longer the one it was read as
+var ErrValueChanged = errors.New("value changed since it was read")
+
+// compareAndSwapScript replaces the value stored against a key, but only if it is still the expected value. The
+// expiration is given in milliseconds, with zero meaning the value does not expire.
+var compareAndSwapScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// compareAndDeleteScript deletes the value stored against a key, but only if it is still the expected value
+var compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])
+`)
+
+// RedisClient is a dis-redis client with the atomic operations needed to change redirects safely when they are
+// changed by more than one request at once, which are run against the underlying go-redis client
+type RedisClient struct {
+	*disRedis.Client
+	client redis.UniversalClient
+}
+
+// NewRedisClient returns a new RedisClient for a single Redis node with the provided config
+func NewRedisClient(ctx context.Context, clientConfig *disRedis.ClientConfig) (*RedisClient, error) {
+	options, err := clientConfig.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting client config: %w", err)
+	}
+
+	return NewRedisClientWithCustomClient(ctx, clientConfig, redis.NewClient(options)), nil
+}
+
+// NewRedisClusterClient returns a new RedisClient for a Redis cluster with the provided config
+func NewRedisClusterClient(ctx context.Context, clientConfig *disRedis.ClientConfig) (*RedisClient, error) {
+	options, err := clientConfig.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting client config: %w", err)
+	}
+
+	clusterClient := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs:    []string{options.Addr},
+		Username: options.Username,
+		NewClient: func(opt *redis.Options) *redis.Client {
+			return redis.NewClient(&redis.Options{
+				Addr:                       opt.Addr,
+				CredentialsProviderContext: options.CredentialsProviderContext,
+				TLSConfig:                  options.TLSConfig,
+			})
+		},
+	})
+
+	return NewRedisClientWithCustomClient(ctx, clientConfig, clusterClient), nil
+}
+
+// NewRedisClientWithCustomClient returns a new RedisClient with the provided go-redis client
+func NewRedisClientWithCustomClient(ctx context.Context, clientConfig *disRedis.ClientConfig, client redis.UniversalClient) *RedisClient {
+	return &RedisClient{
+		Client: disRedis.NewClientWithCustomClient(ctx, clientConfig, client),
+		client: client,
+	}
+}
+
+// SetValueIfAbsent stores the value against the key, but only if no value is stored against it already. It
+// returns true if the value was stored.
+func (cli *RedisClient) SetValueIfAbsent(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	set, err := cli.client.SetNX(ctx, key, value, expiration).Result()
+	if err != nil {
+		return false, fmt.Errorf("error setting value for key %s: %w", key, err)
+	}
+
+	return set, nil
+}
+
+// CompareAndSwapValue replaces the value stored against the key, but only if it is still the expected value.
+// ErrValueChanged is returned if it is not, including when no value is stored against the key.
+func (cli *RedisClient) CompareAndSwapValue(ctx context.Context, key, expected string, value interface{}, expiration time.Duration) error {
+	swapped, err := compareAndSwapScript.Run(ctx, cli.client, []string{key}, expected, value, expiration.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("error swapping value for key %s: %w", key, err)
+	}
+
+	if swapped == 0 {
+		return ErrValueChanged
+	}
+
+	return nil
+}
+
+// DeleteValueReturningPrevious deletes the value stored against the key and returns the value that was deleted.
+// disRedis.ErrKeyNotFound is returned if no value is stored against the key.
+func (cli *RedisClient) DeleteValueReturningPrevious(ctx context.Context, key string) (string, error) {
+	previous, err := cli.client.GetDel(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", disRedis.ErrKeyNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("error deleting value for key %s: %w", key, err)
+	}
+
+	return previous, nil
+}
+
+// CompareAndDeleteValue deletes the value stored against the key, but only if it is still the expected value.
+// ErrValueChanged is returned if it is not, including when no value is stored against the key.
+func (cli *RedisClient) CompareAndDeleteValue(ctx context.Context, key, expected string) error {
+	deleted, err := compareAndDeleteScript.Run(ctx, cli.client, []string{key}, expected).Int()
+	if err != nil {
+		return fmt.Errorf("error deleting value for key %s: %w", key, err)
+	}
+
+	if deleted == 0 {
+		return ErrValueChanged
+	}
+
+	return nil
+}
+
+// AddToSortedSet adds the members to the sorted set stored against the key, all with a score of zero so that
+// they are ordered by value and can be looked up by range with GetSortedSetRangeByLex. Members that are already
+// in the set are left alone.
+func (cli *RedisClient) AddToSortedSet(ctx context.Context, key string, members ...string) error {
+	scored := make([]redis.Z, len(members))
+	for i, member := range members {
+		scored[i] = redis.Z{Member: member}
+	}
+
+	if err := cli.client.ZAdd(ctx, key, scored...).Err(); err != nil {
+		return fmt.Errorf("error adding to sorted set for key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// RemoveFromSortedSet removes the members from the sorted set stored against the key. Members that are not in
+// the set are ignored.
+func (cli *RedisClient) RemoveFromSortedSet(ctx context.Context, key string, members ...string) error {
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		values[i] = member
+	}
+
+	if err := cli.client.ZRem(ctx, key, values...).Err(); err != nil {
+		return fmt.Errorf("error removing from sorted set for key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// GetSortedSetRangeByLex gets the members of the sorted set stored against the key that are between min and
+// max, in order. The bounds are given as for ZRANGEBYLEX, starting with '[' to include the value or '(' to
+// exclude it. No members are returned if no set is stored against the key.
+func (cli *RedisClient) GetSortedSetRangeByLex(ctx context.Context, key, min, max string) ([]string, error) {
+	members, err := cli.client.ZRangeByLex(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error getting range of sorted set for key %s: %w", key, err)
+	}
+
+	return members, nil
+}
+
+// CountExistingKeys returns how many of the given keys have a value stored against them. On a cluster, the keys
+// must all be in the same hash slot.
+func (cli *RedisClient) CountExistingKeys(ctx context.Context, keys ...string) (int64, error) {
+	count, err := cli.client.Exists(ctx, keys...).Result()
+	if err != nil {
+		return 0, fmt.Errorf("error counting existing keys: %w", err)
+	}
+
+	return count, nil
+}